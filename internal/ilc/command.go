@@ -1,6 +1,10 @@
 package ilc
 
 import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
 	"text/template"
 
 	"github.com/evilmarty/ilc/internal/inputs"
@@ -10,6 +14,48 @@ var DefaultShell = []string{"/bin/sh"}
 
 type CommandAliases []string
 
+type CommandArgs struct {
+	Min         int
+	Max         int
+	Description string
+}
+
+func (args CommandArgs) Check(values []string) error {
+	if n := len(values); n < args.Min {
+		return fmt.Errorf("expected at least %d arguments, got %d", args.Min, n)
+	} else if args.Max > 0 && n > args.Max {
+		return fmt.Errorf("expected at most %d arguments, got %d", args.Max, n)
+	}
+	return nil
+}
+
+// Preset is a named set of input values, keyed by input name.
+type Preset struct {
+	Name   string
+	Values map[string]string
+}
+
+// Summary returns the values of the preset, ordered by input name.
+func (preset Preset) Summary() string {
+	var values []string
+	for _, name := range slices.Sorted(maps.Keys(preset.Values)) {
+		values = append(values, name+"="+preset.Values[name])
+	}
+	return strings.Join(values, ", ")
+}
+
+type Presets []Preset
+
+// Get returns the preset with the name.
+func (presets Presets) Get(name string) (Preset, bool) {
+	for _, preset := range presets {
+		if preset.Name == name {
+			return preset, true
+		}
+	}
+	return Preset{}, false
+}
+
 type Inputs struct {
 	*inputs.FlagSet
 }
@@ -20,8 +66,12 @@ type Command struct {
 	Run         string
 	Shell       []string
 	Env         EnvMap
+	EnvPrefix   *string `yaml:"env_prefix"`
 	Pure        bool
+	Remember    *bool
 	Inputs      Inputs
+	Args        *CommandArgs
+	Presets     Presets
 	Commands    SubCommands `yaml:",flow"`
 }
 
@@ -78,6 +128,71 @@ func (command Command) Validate() error {
 		}
 	}
 
+	if command.Inputs.FlagSet != nil {
+		for _, input := range command.Inputs.Inputs() {
+			if when, ok := input.When.(TemplateCondition); ok {
+				_, err := template.New(input.Name).Funcs(defaultTemplateFuncs).Parse(when.Template())
+				if err != nil {
+					return &TemplateError{
+						Type:      "when",
+						Command:   command.Name,
+						FieldName: input.Name,
+						Err:       err,
+					}
+				}
+			}
+			templates := map[string][]string{}
+			switch source := input.OptionsFrom.(type) {
+			case CommandOptions:
+				templates["options_from"] = []string{source.Command}
+			case TemplateOptions:
+				templates["options"] = source.Templates()
+			}
+			if source, ok := input.SuggestionsFrom.(CommandOptions); ok {
+				templates["suggestions_from"] = []string{source.Command}
+			}
+			if defaultTemplate, ok := input.DefaultFrom.(TemplateDefault); ok {
+				templates["default"] = []string{string(defaultTemplate)}
+			}
+			for _, rule := range input.Rules {
+				switch rule := rule.(type) {
+				case TemplateRule:
+					templates["validate"] = append(templates["validate"], TemplateCondition(rule.Template).Template())
+				case CommandRule:
+					templates["validate"] = append(templates["validate"], rule.Command)
+				}
+			}
+			for _, transform := range input.Transforms {
+				if transform, ok := transform.(TemplateTransform); ok {
+					templates["transform"] = append(templates["transform"], string(transform))
+				}
+			}
+			for _, kind := range []string{"options", "options_from", "suggestions_from", "default", "validate", "transform"} {
+				for _, text := range templates[kind] {
+					_, err := template.New(input.Name).Funcs(defaultTemplateFuncs).Parse(text)
+					if err != nil {
+						return &TemplateError{
+							Type:      kind,
+							Command:   command.Name,
+							FieldName: input.Name,
+							Err:       err,
+						}
+					}
+				}
+			}
+		}
+	}
+
+	if prefix := command.EnvPrefix; prefix != nil && *prefix != "" && !validEnvName(*prefix) {
+		return fmt.Errorf("invalid env_prefix in command %q: %q is not a valid environment variable prefix", command.Name, *prefix)
+	}
+
+	if args := command.Args; args != nil {
+		if args.Min < 0 || args.Max < 0 || (args.Max > 0 && args.Min > args.Max) {
+			return fmt.Errorf("invalid args in command %q: min %d and max %d are out of range", command.Name, args.Min, args.Max)
+		}
+	}
+
 	for _, subcommand := range command.Commands {
 		if err := subcommand.Validate(); err != nil {
 			return err
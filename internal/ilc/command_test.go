@@ -58,3 +58,36 @@ func TestCommandGet(t *testing.T) {
 		assert.Equal(t, expected, actual)
 	})
 }
+
+func TestCommandArgsCheck(t *testing.T) {
+	t.Run("within bounds", func(t *testing.T) {
+		args := CommandArgs{Min: 1, Max: 2}
+		assert.NoError(t, args.Check([]string{"a"}))
+		assert.NoError(t, args.Check([]string{"a", "b"}))
+	})
+	t.Run("too few", func(t *testing.T) {
+		args := CommandArgs{Min: 1}
+		assert.ErrorContains(t, args.Check([]string{}), "expected at least 1 arguments, got 0")
+	})
+	t.Run("too many", func(t *testing.T) {
+		args := CommandArgs{Max: 1}
+		assert.ErrorContains(t, args.Check([]string{"a", "b"}), "expected at most 1 arguments, got 2")
+	})
+	t.Run("unbounded max", func(t *testing.T) {
+		args := CommandArgs{}
+		assert.NoError(t, args.Check([]string{"a", "b", "c"}))
+	})
+}
+
+func TestCommandValidateArgs(t *testing.T) {
+	command := Command{Name: "foobar", Args: &CommandArgs{Min: 3, Max: 1}}
+	assert.ErrorContains(t, command.Validate(), `invalid args in command "foobar"`)
+}
+
+func TestCommandValidateEnvPrefix(t *testing.T) {
+	prefix := "APP-"
+	command := Command{Name: "foobar", EnvPrefix: &prefix}
+	assert.ErrorContains(t, command.Validate(), `invalid env_prefix in command "foobar"`)
+	prefix = ""
+	assert.NoError(t, command.Validate())
+}
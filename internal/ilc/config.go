@@ -1,9 +1,13 @@
 package ilc
 
 import (
+	"fmt"
 	"os"
+	"path/filepath"
+	"slices"
 	"strings"
 
+	"github.com/evilmarty/ilc/internal/inputs"
 	"gopkg.in/yaml.v3"
 )
 
@@ -20,13 +24,69 @@ func (config *Config) UnmarshalYAML(node *yaml.Node) error {
 	return nil
 }
 
-func (config Config) Select(args []string) Selection {
+func (config Config) Select(args []string) (Selection, error) {
 	selected := NewSelection(Command(config))
 	return selected.Select(args)
 }
 
 func (config Config) Validate() error {
-	return Command(config).Validate()
+	if err := Command(config).Validate(); err != nil {
+		return err
+	}
+	return validatePresets(NewSelection(Command(config)))
+}
+
+// validatePresets checks the values of the presets of the selected command,
+// and those of its subcommands, are valid for the inputs they're given to.
+// Presets cascade, so their values can be for the inputs of the command, its
+// parents or any of its subcommands.
+func validatePresets(selection Selection) error {
+	command := selection.commands[len(selection.commands)-1]
+	for _, preset := range command.Presets {
+		known := map[string]bool{}
+		if err := checkPreset(selection, preset, known); err != nil {
+			return fmt.Errorf("invalid preset %s in command %q: %w", preset.Name, command.Name, err)
+		}
+		var unknown []string
+		for name := range preset.Values {
+			if !known[name] {
+				unknown = append(unknown, name)
+			}
+		}
+		if len(unknown) > 0 {
+			slices.Sort(unknown)
+			return fmt.Errorf("invalid preset %s in command %q: %w: unknown inputs: %s", preset.Name, command.Name, ErrInvalidValues, strings.Join(unknown, ", "))
+		}
+	}
+	for _, subcommand := range command.Commands {
+		if err := validatePresets(selection.SelectCommand(subcommand.Command, nil)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkPreset checks the values of the preset against the inputs of the
+// selected command and of the subcommands it cascades to, marking the names of
+// the inputs as known. Subcommands with a preset of the same name use theirs.
+func checkPreset(selection Selection, preset Preset, known map[string]bool) error {
+	for _, input := range selection.Inputs().Inputs() {
+		known[input.Name] = true
+		if value, found := preset.Values[input.Name]; found {
+			if err := input.Check(value); err != nil {
+				return fmt.Errorf("invalid value %s for input %s: %w", input.DisplayArg(value), input.Name, err)
+			}
+		}
+	}
+	for _, subcommand := range selection.commands[len(selection.commands)-1].Commands {
+		if _, found := subcommand.Presets.Get(preset.Name); found {
+			continue
+		}
+		if err := checkPreset(selection.SelectCommand(subcommand.Command, nil), preset, known); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func ParseConfig(content []byte) (Config, error) {
@@ -41,9 +101,33 @@ func ParseConfig(content []byte) (Config, error) {
 
 func LoadConfig(path string) (Config, error) {
 	logger.Printf("Attempting to load config file: %s", path)
-	if content, err := os.ReadFile(path); err != nil {
+	content, err := os.ReadFile(path)
+	if err != nil {
 		return Config{}, err
-	} else {
-		return ParseConfig(content)
+	}
+	config, err := ParseConfig(content)
+	if err != nil {
+		return config, err
+	}
+	dir, err := filepath.Abs(filepath.Dir(path))
+	if err != nil {
+		return config, err
+	}
+	resolveBases((*Command)(&config), dir)
+	return config, nil
+}
+
+// resolveBases makes the relative bases of path inputs relative to dir, the
+// directory of the config file, rather than the working directory.
+func resolveBases(command *Command, dir string) {
+	if command.Inputs.FlagSet != nil {
+		for _, input := range command.Inputs.Inputs() {
+			if path, ok := input.Value.(*inputs.PathValue); ok && path.Base != "" && !filepath.IsAbs(path.Base) {
+				path.Base = filepath.Join(dir, path.Base)
+			}
+		}
+	}
+	for i := range command.Commands {
+		resolveBases(&command.Commands[i].Command, dir)
 	}
 }
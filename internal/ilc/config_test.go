@@ -3,6 +3,7 @@ package ilc
 import (
 	"errors"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/evilmarty/ilc/internal/inputs"
@@ -33,7 +34,8 @@ func TestConfigSelect(t *testing.T) {
 			config.Commands[0].Command,
 			config.Commands[0].Command.Commands[1].Command,
 		)
-		actual := config.Select([]string{"foo", "baz"})
+		actual, err := config.Select([]string{"foo", "baz"})
+		assert.NoError(t, err)
 		assert.Equal(t, expected, actual)
 	})
 	t.Run("selects all with remaining args", func(t *testing.T) {
@@ -45,7 +47,8 @@ func TestConfigSelect(t *testing.T) {
 			},
 			Args: []string{"a", "b"},
 		}
-		actual := config.Select([]string{"foo", "baz", "a", "b"})
+		actual, err := config.Select([]string{"foo", "baz", "a", "b"})
+		assert.NoError(t, err)
 		assert.Equal(t, expected, actual)
 	})
 	t.Run("selects some with no remaining args", func(t *testing.T) {
@@ -53,7 +56,8 @@ func TestConfigSelect(t *testing.T) {
 			Command(config),
 			config.Commands[0].Command,
 		)
-		actual := config.Select([]string{"foo"})
+		actual, err := config.Select([]string{"foo"})
+		assert.NoError(t, err)
 		assert.Equal(t, expected, actual)
 	})
 	t.Run("selects some with remaining args", func(t *testing.T) {
@@ -64,12 +68,14 @@ func TestConfigSelect(t *testing.T) {
 			},
 			Args: []string{"a", "b"},
 		}
-		actual := config.Select([]string{"foo", "a", "b"})
+		actual, err := config.Select([]string{"foo", "a", "b"})
+		assert.NoError(t, err)
 		assert.Equal(t, expected, actual)
 	})
 	t.Run("selects none with no remaining args", func(t *testing.T) {
 		expected := NewSelection(Command(config))
-		actual := config.Select([]string{})
+		actual, err := config.Select([]string{})
+		assert.NoError(t, err)
 		assert.Equal(t, expected, actual)
 	})
 	t.Run("selects none with remaining args", func(t *testing.T) {
@@ -79,7 +85,8 @@ func TestConfigSelect(t *testing.T) {
 			},
 			Args: []string{"a", "b"},
 		}
-		actual := config.Select([]string{"a", "b"})
+		actual, err := config.Select([]string{"a", "b"})
+		assert.NoError(t, err)
 		assert.Equal(t, expected, actual)
 	})
 }
@@ -207,11 +214,197 @@ func TestConfigValidate(t *testing.T) {
 	})
 }
 
+func TestConfigValidate_InvalidWhenTemplate(t *testing.T) {
+	config, err := ParseConfig([]byte(`
+inputs:
+  tag:
+    when: "{{ eq .Input.source"
+`))
+	assert.NoError(t, err)
+	err = config.Validate()
+	var tmplErr *TemplateError
+	if assert.True(t, errors.As(err, &tmplErr)) {
+		assert.Equal(t, "when", tmplErr.Type)
+		assert.Equal(t, "tag", tmplErr.FieldName)
+	}
+	assert.ErrorContains(t, err, `invalid when template for input "tag"`)
+}
+
+func TestConfigValidate_InvalidOptionsAndDefaultTemplates(t *testing.T) {
+	config, err := ParseConfig([]byte(`
+inputs:
+  service:
+    options: ["{{ .Input.env"]
+`))
+	assert.NoError(t, err)
+	assert.ErrorContains(t, config.Validate(), `invalid options template for input "service"`)
+
+	config, err = ParseConfig([]byte(`
+inputs:
+  service:
+    default: "{{ .Input.env"
+`))
+	assert.NoError(t, err)
+	assert.ErrorContains(t, config.Validate(), `invalid default template for input "service"`)
+}
+
+func TestConfigValidate_InvalidOptionsFromTemplate(t *testing.T) {
+	config, err := ParseConfig([]byte(`
+inputs:
+  branch:
+    options_from: "git branch {{ .Input.remote"
+`))
+	assert.NoError(t, err)
+	err = config.Validate()
+	var tmplErr *TemplateError
+	if assert.True(t, errors.As(err, &tmplErr)) {
+		assert.Equal(t, "options_from", tmplErr.Type)
+		assert.Equal(t, "branch", tmplErr.FieldName)
+	}
+	assert.ErrorContains(t, err, `invalid options_from template for input "branch"`)
+}
+
+func TestConfigValidate_Presets(t *testing.T) {
+	parse := func(presets string) Config {
+		content := `
+inputs:
+  env:
+    options: [staging, prod]
+commands:
+  deploy:
+    inputs:
+      replicas:
+        type: integer
+        min: 1
+        max: 5
+    presets:
+` + presets + `
+    run: echo deploy
+`
+		config, err := ParseConfig([]byte(content))
+		assert.NoError(t, err)
+		return config
+	}
+
+	t.Run("valid", func(t *testing.T) {
+		config := parse("      prod-full:\n        env: prod\n        replicas: 5")
+		assert.NoError(t, config.Validate())
+		assert.Equal(t, "0", config.Commands[0].Inputs.Inputs()[0].Value.String())
+	})
+
+	t.Run("invalid value", func(t *testing.T) {
+		config := parse("      prod-full:\n        env: production")
+		assert.ErrorContains(t, config.Validate(), `invalid preset prod-full in command "deploy": invalid value "production" for input env`)
+		config = parse("      prod-full:\n        replicas: 9")
+		assert.ErrorContains(t, config.Validate(), `invalid value "9" for input replicas`)
+	})
+
+	t.Run("unknown input", func(t *testing.T) {
+		config := parse("      prod-full:\n        region: eu")
+		assert.EqualError(t, config.Validate(), `invalid preset prod-full in command "deploy": invalid input values: unknown inputs: region`)
+	})
+}
+
+func TestConfigValidate_CascadingPresets(t *testing.T) {
+	parse := func(presets string) Config {
+		content := `
+inputs:
+  region:
+    options: [eu, us]
+presets:
+` + presets + `
+commands:
+  deploy:
+    inputs:
+      env:
+        options: [staging, prod]
+    presets:
+      canary:
+        env: staging
+    run: echo deploy
+  status:
+    inputs:
+      env: string
+    run: echo status
+`
+		config, err := ParseConfig([]byte(content))
+		assert.NoError(t, err)
+		return config
+	}
+
+	t.Run("subcommand inputs", func(t *testing.T) {
+		assert.NoError(t, parse("  prod:\n    region: eu\n    env: prod").Validate())
+	})
+
+	t.Run("invalid subcommand value", func(t *testing.T) {
+		config := parse("  prod:\n    region: eu\n    env: production")
+		assert.EqualError(t, config.Validate(), `invalid preset prod in command "": invalid value "production" for input env: not one of the options: staging, prod`)
+	})
+
+	t.Run("replaced in subcommand", func(t *testing.T) {
+		assert.NoError(t, parse("  canary:\n    env: qa").Validate(), "subcommands use their own preset of the same name")
+	})
+
+	t.Run("unknown input", func(t *testing.T) {
+		config := parse("  prod:\n    zone: a")
+		assert.EqualError(t, config.Validate(), `invalid preset prod in command "": invalid input values: unknown inputs: zone`)
+	})
+}
+
+func TestConfigValidate_InvalidValidateTemplate(t *testing.T) {
+	config, err := ParseConfig([]byte("inputs:\n  name:\n    validate:\n      - lt (len .Value 50\n"))
+	assert.NoError(t, err)
+	assert.ErrorContains(t, config.Validate(), `invalid validate template for input "name"`)
+}
+
+func TestConfigValidate_InvalidTransformTemplate(t *testing.T) {
+	config, err := ParseConfig([]byte("inputs:\n  name:\n    transform:\n      - template: '{{ .Value'\n"))
+	assert.NoError(t, err)
+	assert.ErrorContains(t, config.Validate(), `invalid transform template for input "name"`)
+}
+
+func TestConfigValidate_InvalidSuggestionsFromTemplate(t *testing.T) {
+	config, err := ParseConfig([]byte("inputs:\n  branch:\n    suggestions_from: git branch {{ .Input.repo\n"))
+	assert.NoError(t, err)
+	assert.ErrorContains(t, config.Validate(), `invalid suggestions_from template for input "branch"`)
+}
+
 func TestLoadConfig_FileNotExist(t *testing.T) {
 	_, err := LoadConfig("non_existent_file.yml")
 	assert.Error(t, err)
 }
 
+func TestLoadConfig_PathBase(t *testing.T) {
+	content := `
+inputs:
+  relative:
+    type: path
+    base: configs
+  absolute:
+    type: path
+    base: /etc
+commands:
+  test:
+    run: go test
+    inputs:
+      nested:
+        type: path
+        base: ..
+      cwd: path
+`
+	dir := t.TempDir()
+	path := filepath.Join(dir, "ilc.yml")
+	assert.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+
+	config, err := LoadConfig(path)
+	assert.NoError(t, err)
+	bases := []string{}
+	for _, input := range append(config.Inputs.Inputs(), config.Commands[0].Inputs.Inputs()...) {
+		bases = append(bases, input.Value.(*inputs.PathValue).Base)
+	}
+	assert.Equal(t, []string{filepath.Join(dir, "configs"), "/etc", filepath.Dir(dir), ""}, bases)
+}
+
 func TestLoadConfig_DescriptionTrimming(t *testing.T) {
 	content := `
 description: |
@@ -248,3 +441,18 @@ commands:
 	assert.Equal(t, "Input description with newlines.", inputs[0].Description)
 }
 
+
+func TestLoadConfig_Args(t *testing.T) {
+	content := `
+commands:
+  test:
+    run: go test
+    args:
+      min: 1
+      max: 3
+      description: Packages to test
+`
+	config, err := ParseConfig([]byte(content))
+	assert.NoError(t, err)
+	assert.Equal(t, &CommandArgs{Min: 1, Max: 3, Description: "Packages to test"}, config.Commands[0].Args)
+}
@@ -2,9 +2,9 @@ package ilc
 
 import "fmt"
 
-// TemplateError represents a syntax or parsing error in a command's run script or environment template.
+// TemplateError represents a syntax or parsing error in a command's run, env or input template.
 type TemplateError struct {
-	Type      string // "run" or "env"
+	Type      string // "run", "env" or the input field name, e.g. "when"
 	Command   string
 	FieldName string
 	Err       error
@@ -14,6 +14,9 @@ func (e *TemplateError) Error() string {
 	if e.Type == "run" {
 		return fmt.Sprintf("invalid run template in command %q: %v", e.Command, e.Err)
 	}
+	if e.Type != "env" {
+		return fmt.Sprintf("invalid %s template for input %q in command %q: %v", e.Type, e.FieldName, e.Command, e.Err)
+	}
 	return fmt.Sprintf("invalid env template %q in command %q: %v", e.FieldName, e.Command, e.Err)
 }
 
@@ -6,6 +6,9 @@ import (
 	"strings"
 	"unicode/utf8"
 
+	"github.com/charmbracelet/bubbles/filepicker"
+	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/bubbles/textarea"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
@@ -20,6 +23,8 @@ var (
 	descActiveStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("244"))
 	helpStyle       = lipgloss.NewStyle().Foreground(lipgloss.Color("241")).Italic(true)
 	progressStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
+	groupStyle      = lipgloss.NewStyle().Foreground(lipgloss.Color("32"))
+	disabledStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("238"))
 	errorStyle      = lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Bold(true)
 	cmdPathStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("255"))
 	neutralPromptStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
@@ -32,8 +37,12 @@ type commandMode int
 const (
 	modeCommandSelect commandMode = iota
 	modeInputPrompt
+	modePresetSelect
 )
 
+// noPreset is the label of the choice to use none of the presets.
+const noPreset = "(none)"
+
 type commandModel struct {
 	title         string
 	history       []Selection
@@ -46,9 +55,20 @@ type commandModel struct {
 	missing      []*inputs.Input
 	inputIndex   int
 	textInput    textinput.Model
+	textArea     textarea.Model
 	optionsIndex int
+	checked      []bool
 	inputErr     error
+	useDefaults  bool
+	loading      bool
+	spinner      spinner.Model
+	picking      bool
+	picker       filepicker.Model
+	other        bool
 	env          map[string]string
+	values       map[string]string
+	preset       string
+	askPreset    bool
 	width        int
 	height       int
 }
@@ -63,18 +83,32 @@ func (m *commandModel) currentSubcommands() SubCommands {
 
 func (m *commandModel) initCurrentInput() {
 	m.inputErr = nil
+	m.loading = false
+	m.picking = false
+	m.other = false
 	if len(m.missing) == 0 {
 		return
 	}
 	current := m.missing[m.inputIndex]
-	if !current.Selectable() && !m.isBooleanInput(current) {
-		m.textInput = textinput.New()
-		m.textInput.SetValue(current.Value.String())
-		m.textInput.Placeholder = current.Value.String()
-		m.textInput.PlaceholderStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("244"))
-		m.textInput.Focus()
-	} else {
+	if current.OptionsFrom != nil {
+		m.loading = true
 		m.optionsIndex = 0
+		return
+	}
+	if picker, ok := inputs.PathPicker(current); ok {
+		m.picker = picker
+		m.picking = true
+		return
+	}
+	if current.Multiline() {
+		m.textArea = inputs.NewTextArea(current)
+		return
+	}
+	if !m.choosing(current) {
+		m.initTextInput(current, current.RawValue().String())
+	} else {
+		m.optionsIndex = inputs.FirstOption(inputs.ChoiceOptions(current))
+		m.checked = inputs.CheckedOptions(current.Options, current.Value)
 		if m.isBooleanInput(current) {
 			opts := m.getBooleanOptions(current)
 			targetVal := "false"
@@ -92,8 +126,60 @@ func (m *commandModel) initCurrentInput() {
 	}
 }
 
+// initTextInput starts typing the value of the input from value.
+func (m *commandModel) initTextInput(current *inputs.Input, value string) {
+	m.textInput = textinput.New()
+	m.textInput.SetValue(value)
+	m.textInput.Placeholder = inputs.Placeholder(current)
+	m.textInput.PlaceholderStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("244"))
+	if current.Secret {
+		m.textInput.EchoMode = textinput.EchoPassword
+		m.textInput.EchoCharacter = '•'
+		m.textInput.Placeholder = ""
+	}
+	inputs.Complete(&m.textInput, current)
+	inputs.Suggest(&m.textInput, current, current.Suggestions)
+	m.textInput.Focus()
+}
+
 func (m *commandModel) Init() tea.Cmd {
-	return textinput.Blink
+	return tea.Batch(textinput.Blink, m.startInput())
+}
+
+// startInput returns the command the current input starts with, reading the
+// directory of its picker or loading its options or suggestions.
+func (m *commandModel) startInput() tea.Cmd {
+	if m.picking {
+		return m.picker.Init()
+	}
+	return tea.Batch(m.loadOptions(), m.loadSuggestions())
+}
+
+// loadOptions returns the command loading the options of the current input,
+// if it is waiting on them.
+func (m *commandModel) loadOptions() tea.Cmd {
+	if !m.loading {
+		return nil
+	}
+	m.spinner = spinner.New(spinner.WithSpinner(spinner.Dot))
+	return tea.Batch(m.spinner.Tick, inputs.LoadOptions(m.currentSelection().Inputs().FlagSet, m.missing[m.inputIndex]))
+}
+
+// loadSuggestions returns the command loading the suggestions of the current
+// input, if it is typed.
+func (m *commandModel) loadSuggestions() tea.Cmd {
+	if m.loading || m.inputIndex < 0 || m.inputIndex >= len(m.missing) {
+		return nil
+	}
+	current := m.missing[m.inputIndex]
+	if current.SuggestionsFrom == nil || current.Multiline() || m.choosing(current) {
+		return nil
+	}
+	var fs *inputs.FlagSet
+	if len(m.history) > 0 {
+		fs = m.currentSelection().Inputs().FlagSet
+	}
+	return inputs.LoadSuggestions(fs, current)
 }
 
 func (m *commandModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
@@ -107,16 +193,69 @@ func (m *commandModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	if m.mode == modeInputPrompt {
 		switch msg := msg.(type) {
+		case inputs.OptionsLoadedMsg:
+			current := m.missing[m.inputIndex]
+			if msg.Input != current || !m.loading {
+				return m, nil
+			}
+			m.loading = false
+			m.inputErr = msg.Err
+			current.Options = msg.Options
+			m.optionsIndex = inputs.OptionIndex(msg.Options, current.Value.String())
+			m.checked = inputs.CheckedOptions(msg.Options, current.Value)
+			return m, nil
+
+		case inputs.SuggestionsLoadedMsg:
+			if current := m.missing[m.inputIndex]; msg.Input == current {
+				inputs.Suggest(&m.textInput, current, msg.Suggestions)
+			}
+			return m, nil
+
+		case spinner.TickMsg:
+			if !m.loading {
+				return m, nil
+			}
+			m.spinner, cmd = m.spinner.Update(msg)
+			return m, cmd
+
+		case inputs.EditorClosedMsg:
+			if msg.Input != m.missing[m.inputIndex] {
+				return m, nil
+			}
+			m.inputErr = msg.Err
+			if msg.Err == nil {
+				m.textArea.SetValue(msg.Text)
+			}
+			return m, nil
+
 		case tea.KeyMsg:
+			if m.picking && msg.Type != tea.KeyCtrlC && msg.Type != tea.KeyEsc && msg.Type != tea.KeyCtrlD {
+				return m.updatePicker(msg)
+			}
+			if m.missing[m.inputIndex].Multiline() && msg.Type != tea.KeyCtrlC && msg.Type != tea.KeyEsc && msg.Type != tea.KeyCtrlD {
+				return m.updateTextArea(msg)
+			}
 			switch msg.Type {
 			case tea.KeyCtrlC:
 				m.aborted = true
 				return m, tea.Quit
 
 			case tea.KeyEsc:
-				if m.inputIndex > 0 {
-					m.inputIndex--
-					m.initCurrentInput()
+				if m.other {
+					m.other = false
+					m.inputErr = nil
+					return m, nil
+				}
+				for i := m.inputIndex - 1; i >= 0; i-- {
+					if m.applies(m.missing[i]) {
+						m.inputIndex = i
+						m.initCurrentInput()
+						return m, m.startInput()
+					}
+				}
+				if m.offersPresets() {
+					m.mode = modePresetSelect
+					m.selectedIndex = m.presetIndex()
 					return m, nil
 				}
 				// Go back to command selection mode
@@ -129,40 +268,67 @@ func (m *commandModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 			case tea.KeyEnter:
 				current := m.missing[m.inputIndex]
+				if m.loading || (m.choosing(current) && len(m.getBooleanOptions(current)) == 0) {
+					return m, nil
+				}
+				if m.choosing(current) && inputs.IsOther(current, m.optionsIndex) {
+					m.other = true
+					m.inputErr = nil
+					m.initTextInput(current, inputs.OtherValue(current))
+					return m, m.loadSuggestions()
+				}
+				if list, ok := current.Value.(*inputs.ListValue); ok && inputs.IsMultiSelect(current) {
+					values := inputs.CheckedValues(current.Options, m.checked)
+					if err := m.confirmValue(current, func() error { return list.SetItems(values) }); err != nil {
+						m.inputErr = err
+						return m, nil
+					}
+					m.inputErr = nil
+					return m.advanceInput(m.inputIndex + 1)
+				}
 				var val string
-				if current.Selectable() || m.isBooleanInput(current) {
-					opts := m.getBooleanOptions(current)
-					val = opts[m.optionsIndex].Value
+				if m.choosing(current) {
+					option := m.getBooleanOptions(current)[m.optionsIndex]
+					if option.Disabled != "" {
+						return m, nil
+					}
+					val = option.Value
 				} else {
 					val = m.textInput.Value()
 					if val == "" {
-						val = current.Value.String()
+						val = current.RawValue().String()
 					}
 				}
 
-				if err := current.Value.Set(val); err != nil {
+				if err := m.confirmValue(current, func() error { return current.Value.Set(val) }); err != nil {
 					m.inputErr = err
 					return m, nil
 				}
 
 				m.inputErr = nil
-				if m.inputIndex < len(m.missing)-1 {
-					m.inputIndex++
-					m.initCurrentInput()
+				return m.advanceInput(m.inputIndex + 1)
+
+			case tea.KeyCtrlD:
+				m.useDefaults = true
+				return m.advanceInput(m.inputIndex)
+
+			case tea.KeySpace:
+				if current := m.missing[m.inputIndex]; inputs.IsMultiSelect(current) {
+					if !m.loading && m.optionsIndex < len(m.checked) && current.Options[m.optionsIndex].Disabled == "" {
+						m.checked[m.optionsIndex] = !m.checked[m.optionsIndex]
+						m.inputErr = nil
+					}
 					return m, nil
 				}
-				m.done = true
-				return m, tea.Quit
 
 			case tea.KeyUp:
 				current := m.missing[m.inputIndex]
-				if current.Selectable() || m.isBooleanInput(current) {
+				if m.choosing(current) {
 					opts := m.getBooleanOptions(current)
-					if m.optionsIndex > 0 {
-						m.optionsIndex--
-					} else {
-						m.optionsIndex = len(opts) - 1
+					if len(opts) == 0 {
+						return m, nil
 					}
+					m.optionsIndex = inputs.NextOption(opts, m.optionsIndex, -1)
 				} else if adjustable, ok := current.Value.(inputs.AdjustableValue); ok {
 					newStr, err := adjustable.Adjust(m.textInput.Value(), 1)
 					m.textInput.SetValue(newStr)
@@ -172,13 +338,12 @@ func (m *commandModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 			case tea.KeyDown:
 				current := m.missing[m.inputIndex]
-				if current.Selectable() || m.isBooleanInput(current) {
+				if m.choosing(current) {
 					opts := m.getBooleanOptions(current)
-					if m.optionsIndex < len(opts)-1 {
-						m.optionsIndex++
-					} else {
-						m.optionsIndex = 0
+					if len(opts) == 0 {
+						return m, nil
 					}
+					m.optionsIndex = inputs.NextOption(opts, m.optionsIndex, 1)
 				} else if adjustable, ok := current.Value.(inputs.AdjustableValue); ok {
 					newStr, err := adjustable.Adjust(m.textInput.Value(), -1)
 					m.textInput.SetValue(newStr)
@@ -188,28 +353,32 @@ func (m *commandModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 		}
 
+		if m.picking {
+			return m.updatePicker(msg)
+		}
+
 		current := m.missing[m.inputIndex]
-		if !current.Selectable() {
+		if current.Multiline() {
+			m.textArea, cmd = m.textArea.Update(msg)
+		} else if !current.Selectable() || m.other {
 			m.textInput, cmd = m.textInput.Update(msg)
+			inputs.Complete(&m.textInput, current)
 
 			// Live validation dry-run
 			val := m.textInput.Value()
 			if val == "" {
-				val = current.Value.String()
-			}
-
-			var err error
-			if validator, ok := current.Value.(inputs.LiveValidator); ok {
-				err = validator.ValidateLive(val)
-			} else {
-				err = current.Value.Set(val)
+				val = current.RawValue().String()
 			}
 
-			m.inputErr = err
+			m.inputErr = m.currentSelection().Inputs().ValidateLive(current, val)
 		}
 		return m, cmd
 	}
 
+	if m.mode == modePresetSelect {
+		return m.updatePresetSelect(msg)
+	}
+
 	// modeCommandSelect
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
@@ -235,25 +404,13 @@ func (m *commandModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			nextSel := m.currentSelection().SelectCommand(choice.Command, m.currentSelection().Args)
 
 			if nextSel.Runnable() {
-				inps := nextSel.Inputs()
-				missing, err := inps.ParseEnvAndArgs(nextSel.Args, m.env)
-				if err != nil {
-					m.inputErr = err
+				m.history = append(m.history, nextSel)
+				if m.offersPresets() {
+					m.mode = modePresetSelect
+					m.selectedIndex = 0
 					return m, nil
 				}
-
-				if len(missing) == 0 {
-					m.history = append(m.history, nextSel)
-					m.done = true
-					return m, tea.Quit
-				}
-
-				m.history = append(m.history, nextSel)
-				m.missing = missing
-				m.inputIndex = 0
-				m.mode = modeInputPrompt
-				m.initCurrentInput()
-				return m, nil
+				return m.promptInputs()
 			}
 
 			m.history = append(m.history, nextSel)
@@ -286,6 +443,114 @@ func (m *commandModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+// updatePresetSelect chooses the preset, or none, whose values the inputs of
+// the selected command start with.
+func (m *commandModel) updatePresetSelect(msg tea.Msg) (tea.Model, tea.Cmd) {
+	key, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+	choices := len(m.currentSelection().Presets()) + 1
+	switch key.Type {
+	case tea.KeyCtrlC:
+		m.aborted = true
+		return m, tea.Quit
+
+	case tea.KeyEsc:
+		m.mode = modeCommandSelect
+		m.selectedIndex = 0
+		if len(m.history) > 1 {
+			m.history = m.history[:len(m.history)-1]
+		}
+		return m, nil
+
+	case tea.KeyEnter:
+		m.preset = ""
+		if m.selectedIndex > 0 {
+			m.preset = m.currentSelection().Presets()[m.selectedIndex-1].Name
+		}
+		return m.promptInputs()
+
+	case tea.KeyUp:
+		m.selectedIndex = (m.selectedIndex + choices - 1) % choices
+
+	case tea.KeyDown:
+		m.selectedIndex = (m.selectedIndex + 1) % choices
+	}
+	return m, nil
+}
+
+// promptInputs parses the inputs of the selected command, which is runnable,
+// and prompts for those missing, finishing when there are none.
+func (m *commandModel) promptInputs() (tea.Model, tea.Cmd) {
+	sel := m.currentSelection()
+	values, err := sel.PresetValues(m.preset, m.values)
+	var missing []*inputs.Input
+	if err == nil {
+		missing, err = sel.Inputs().ParseValuesEnvAndArgs(values, sel.InputArgs(), m.env)
+	}
+	if err != nil {
+		m.inputErr = err
+		if m.mode == modePresetSelect {
+			m.selectedIndex = 0
+		}
+		m.mode = modeCommandSelect
+		m.history = m.history[:len(m.history)-1]
+		return m, nil
+	}
+
+	if m.useDefaults {
+		missing = inputs.Required(missing)
+	}
+
+	if len(missing) == 0 {
+		m.done = true
+		return m, tea.Quit
+	}
+
+	m.missing = missing
+	m.inputIndex = -1
+	m.mode = modeInputPrompt
+	return m.advanceInput(0)
+}
+
+// offersPresets reports whether the presets of the selected command are to be
+// chosen from, as none was given.
+func (m *commandModel) offersPresets() bool {
+	return m.askPreset && len(m.currentSelection().Presets()) > 0
+}
+
+// presetIndex returns the index of the chosen preset among the choices.
+func (m *commandModel) presetIndex() int {
+	for i, preset := range m.currentSelection().Presets() {
+		if preset.Name == m.preset {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// updateTextArea passes the key to the text area of a multiline input, which
+// is confirmed with Ctrl+S so Enter can start a new line.
+func (m *commandModel) updateTextArea(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	current := m.missing[m.inputIndex]
+	switch msg.Type {
+	case tea.KeyCtrlS:
+		if err := m.confirmValue(current, func() error { return current.Value.Set(m.textArea.Value()) }); err != nil {
+			m.inputErr = err
+			return m, nil
+		}
+		m.inputErr = nil
+		return m.advanceInput(m.inputIndex + 1)
+
+	case tea.KeyCtrlE:
+		return m, inputs.OpenEditor(current, m.textArea.Value())
+	}
+	var cmd tea.Cmd
+	m.textArea, cmd = m.textArea.Update(msg)
+	return m, cmd
+}
+
 func (m *commandModel) View() string {
 	if m.aborted {
 		return ""
@@ -312,8 +577,14 @@ func (m *commandModel) View() string {
 	if m.done {
 		var exitSb strings.Builder
 		exitSb.WriteString(fmt.Sprintf("%s%s\n", titleStyle.Render("Command:"), bcStyled.String()))
+		if m.preset != "" {
+			exitSb.WriteString(titleStyle.Render("Preset:") + " " + cmdPathStyle.Render(m.preset) + "\n")
+		}
 		for _, completed := range m.missing {
-			exitSb.WriteString(titleStyle.Render(completed.Name+":") + " " + cmdPathStyle.Render(completed.Value.String()) + "\n")
+			if !m.applies(completed) {
+				continue
+			}
+			exitSb.WriteString(titleStyle.Render(completed.Name+":") + " " + cmdPathStyle.Render(completed.DisplayValue()) + "\n")
 		}
 		return exitSb.String()
 	}
@@ -329,67 +600,44 @@ func (m *commandModel) View() string {
 			sb.WriteString(titleStyle.Render(" ❯"))
 		}
 	}
-	if m.mode == modeInputPrompt {
+	if m.mode != modeCommandSelect {
 		sb.WriteString("\n")
 	} else {
 		sb.WriteString("\n\n")
 	}
 
 	if m.mode == modeCommandSelect {
-		subs := m.currentSubcommands()
-		maxLen := 0
-		for _, sub := range subs {
-			maxLen = max(maxLen, utf8.RuneCountInString(sub.Name))
+		var names, descriptions []string
+		for _, sub := range m.currentSubcommands() {
+			names = append(names, sub.Name)
+			descriptions = append(descriptions, sub.Description)
 		}
+		m.viewChoices(&sb, names, descriptions)
 
-		for i, sub := range subs {
-			padLen := maxLen - utf8.RuneCountInString(sub.Name)
-			if padLen < 0 {
-				padLen = 0
-			}
-			padding := strings.Repeat(" ", padLen+5)
-
-			var nameStr string
-			var descStr string
-
-			if i == m.selectedIndex {
-				nameStr = accentStyle.Render(sub.Name)
-				if sub.Description != "" {
-					wrapWidth := m.width - (maxLen + 9)
-					if wrapWidth < 20 {
-						wrapWidth = 20
-					}
-					desc := sub.Description
-					if utf8.RuneCountInString(desc) > wrapWidth {
-						desc = truncateText(desc, wrapWidth)
-					}
-					descStr = descActiveStyle.Render(padding + desc)
-				}
-				sb.WriteString(fmt.Sprintf("  ❯ %s%s\n", nameStr, descStr))
-			} else {
-				nameStr = dimStyle.Render(sub.Name)
-				if sub.Description != "" {
-					wrapWidth := m.width - (maxLen + 9)
-					if wrapWidth < 20 {
-						wrapWidth = 20
-					}
-					desc := sub.Description
-					if utf8.RuneCountInString(desc) > wrapWidth {
-						desc = truncateText(desc, wrapWidth)
-					}
-					descStr = descDimStyle.Render(padding + desc)
-				}
-				sb.WriteString(fmt.Sprintf("    %s%s\n", nameStr, descStr))
-			}
+		sb.WriteString("\n" + helpStyle.Render("  [Enter] Select/Confirm  •  [Esc] Back  •  [Ctrl+C] Abort") + "\n")
+	} else if m.mode == modePresetSelect {
+		sb.WriteString(titleStyle.Render("Preset:") + "\n\n")
+		names := []string{noPreset}
+		descriptions := []string{"Enter the inputs without a preset"}
+		for _, preset := range m.currentSelection().Presets() {
+			names = append(names, preset.Name)
+			descriptions = append(descriptions, preset.Summary())
 		}
+		m.viewChoices(&sb, names, descriptions)
 
-		sb.WriteString("\n" + helpStyle.Render("  [Enter] Select/Confirm  •  [Esc] Back  •  [Ctrl+C] Abort") + "\n")
+		sb.WriteString("\n" + helpStyle.Render("  [Enter] Select  •  [Esc] Back  •  [Ctrl+C] Abort") + "\n")
 	} else {
 		// modeInputPrompt
+		if m.preset != "" {
+			sb.WriteString(titleStyle.Render("Preset:") + " " + cmdPathStyle.Render(m.preset) + "\n")
+		}
 		// Render completed inputs in progressive/condensed form
 		for i := 0; i < m.inputIndex; i++ {
 			completed := m.missing[i]
-			sb.WriteString(titleStyle.Render(completed.Name+":") + " " + cmdPathStyle.Render(completed.Value.String()) + "\n")
+			if !m.applies(completed) {
+				continue
+			}
+			sb.WriteString(titleStyle.Render(completed.Name+":") + " " + cmdPathStyle.Render(completed.DisplayValue()) + "\n")
 		}
 
 		current := m.missing[m.inputIndex]
@@ -404,14 +652,38 @@ func (m *commandModel) View() string {
 		sb.WriteString("\n")
 
 		// Render active input control
-		if current.Selectable() || m.isBooleanInput(current) {
+		if m.loading {
+			sb.WriteString("\n    " + m.spinner.View() + dimStyle.Render("Loading options…") + "\n")
+		} else if m.picking {
+			sb.WriteString("\n    " + dimStyle.Render(m.picker.CurrentDirectory) + "\n" + m.picker.View() + "\n")
+		} else if current.Multiline() {
+			sb.WriteString("\n    " + strings.ReplaceAll(m.textArea.View(), "\n", "\n    ") + "\n")
+		} else if m.choosing(current) {
 			sb.WriteString("\n")
 			opts := m.getBooleanOptions(current)
+			width := inputs.LabelWidth(opts)
 			for i, option := range opts {
-				if i == m.optionsIndex {
-					sb.WriteString(fmt.Sprintf("    ❯ %s\n", accentStyle.Render(option.Label)))
+				if group, ok := inputs.GroupHeading(opts, i); ok {
+					sb.WriteString(fmt.Sprintf("    %s\n", groupStyle.Render(group)))
+				}
+				label := option.Label
+				if inputs.IsMultiSelect(current) {
+					label = checkbox(i < len(m.checked) && m.checked[i]) + label
+				}
+				var desc string
+				if s := inputs.OptionDescription(option); s != "" {
+					wrapWidth := max(m.width-(width+11), 20)
+					if utf8.RuneCountInString(s) > wrapWidth {
+						s = truncateText(s, wrapWidth)
+					}
+					desc = strings.Repeat(" ", width-utf8.RuneCountInString(option.Label)+3) + s
+				}
+				if option.Disabled != "" {
+					sb.WriteString(fmt.Sprintf("      %s\n", disabledStyle.Render(label+desc)))
+				} else if i == m.optionsIndex {
+					sb.WriteString(fmt.Sprintf("    ❯ %s%s\n", accentStyle.Render(label), descActiveStyle.Render(desc)))
 				} else {
-					sb.WriteString(fmt.Sprintf("      %s\n", dimStyle.Render(option.Label)))
+					sb.WriteString(fmt.Sprintf("      %s%s\n", dimStyle.Render(label), descDimStyle.Render(desc)))
 				}
 			}
 		} else {
@@ -431,16 +703,83 @@ func (m *commandModel) View() string {
 		if _, isAdjustable := current.Value.(inputs.AdjustableValue); isAdjustable {
 			helpParts = append(helpParts, "[Up/Down] +/-")
 		}
-		if m.inputErr == nil {
+		if inputs.IsMultiSelect(current) {
+			helpParts = append(helpParts, "[Space] Toggle")
+		}
+		if m.picking {
+			helpParts = append(helpParts, "[Left/Right] Browse", "[Enter] Select")
+		} else if current.Multiline() {
+			helpParts = append(helpParts, "[Ctrl+E] Editor")
+			if m.inputErr == nil {
+				helpParts = append(helpParts, "[Ctrl+S] Confirm")
+			}
+		} else if m.inputErr == nil && !m.loading {
 			helpParts = append(helpParts, "[Enter] Confirm")
 		}
-		helpParts = append(helpParts, "[Esc] Back", "[Ctrl+C] Abort")
+		if m.hasOptionalInputs() {
+			helpParts = append(helpParts, "[Ctrl+D] Use defaults")
+		}
+		if m.other {
+			helpParts = append(helpParts, "[Esc] Options", "[Ctrl+C] Abort")
+		} else {
+			helpParts = append(helpParts, "[Esc] Back", "[Ctrl+C] Abort")
+		}
 		sb.WriteString("\n" + helpStyle.Render("  "+strings.Join(helpParts, "  •  ")) + "\n")
 	}
 
 	return sb.String()
 }
 
+// viewChoices renders the names with their descriptions aligned beside them,
+// marking the selected one.
+func (m *commandModel) viewChoices(sb *strings.Builder, names, descriptions []string) {
+	maxLen := 0
+	for _, name := range names {
+		maxLen = max(maxLen, utf8.RuneCountInString(name))
+	}
+
+	for i, name := range names {
+		padLen := maxLen - utf8.RuneCountInString(name)
+		if padLen < 0 {
+			padLen = 0
+		}
+		padding := strings.Repeat(" ", padLen+5)
+
+		var nameStr string
+		var descStr string
+
+		if i == m.selectedIndex {
+			nameStr = accentStyle.Render(name)
+			if descriptions[i] != "" {
+				wrapWidth := m.width - (maxLen + 9)
+				if wrapWidth < 20 {
+					wrapWidth = 20
+				}
+				desc := descriptions[i]
+				if utf8.RuneCountInString(desc) > wrapWidth {
+					desc = truncateText(desc, wrapWidth)
+				}
+				descStr = descActiveStyle.Render(padding + desc)
+			}
+			sb.WriteString(fmt.Sprintf("  ❯ %s%s\n", nameStr, descStr))
+		} else {
+			nameStr = dimStyle.Render(name)
+			if descriptions[i] != "" {
+				wrapWidth := m.width - (maxLen + 9)
+				if wrapWidth < 20 {
+					wrapWidth = 20
+				}
+				desc := descriptions[i]
+				if utf8.RuneCountInString(desc) > wrapWidth {
+					desc = truncateText(desc, wrapWidth)
+				}
+				descStr = descDimStyle.Render(padding + desc)
+			}
+			sb.WriteString(fmt.Sprintf("    %s%s\n", nameStr, descStr))
+		}
+	}
+}
+
 type programRunner interface {
 	Run() (tea.Model, error)
 }
@@ -449,7 +788,7 @@ var newProgram = func(m tea.Model) programRunner {
 	return tea.NewProgram(m)
 }
 
-func askCommands(sel Selection, env map[string]string) (Selection, error) {
+func askCommands(sel Selection, env map[string]string, values map[string]string, preset string, acceptDefaults bool) (Selection, error) {
 	title := sel.commands[0].Description
 	if title == "" {
 		title = sel.commands[0].Name
@@ -471,19 +810,35 @@ func askCommands(sel Selection, env map[string]string) (Selection, error) {
 		history:       history,
 		selectedIndex: 0,
 		mode:          modeCommandSelect,
+		useDefaults:   acceptDefaults,
 		env:           env,
+		values:        values,
+		preset:        preset,
+		askPreset:     preset == "",
 		width:         80,
 		height:        24,
 	}
 
-	if sel.Runnable() {
+	if sel.Runnable() && m.offersPresets() {
+		m.mode = modePresetSelect
+	} else if sel.Runnable() {
+		presetValues, err := sel.PresetValues(preset, values)
+		if err != nil {
+			return sel, err
+		}
 		inps := sel.Inputs()
-		missing, err := inps.ParseEnvAndArgs(sel.Args, env)
+		missing, err := inps.ParseValuesEnvAndArgs(presetValues, sel.InputArgs(), env)
+		if acceptDefaults {
+			missing = inputs.Required(missing)
+		}
 		if err == nil && len(missing) > 0 {
 			m.missing = missing
-			m.inputIndex = 0
+			m.inputIndex = -1
 			m.mode = modeInputPrompt
-			m.initCurrentInput()
+			if m.advanceInput(0); m.done {
+				// None of the missing inputs apply
+				return sel, nil
+			}
 		}
 	}
 
@@ -507,6 +862,109 @@ func askCommands(sel Selection, env map[string]string) (Selection, error) {
 	return sel, errors.New("no choice made")
 }
 
+// advanceInput moves to the next input from start that needs prompting,
+// skipping optional inputs once defaults have been accepted, and finishes
+// when none remain. Templated defaults are rendered from the answers so far as
+// inputs are reached, so changing an earlier answer re-renders them.
+func (m *commandModel) advanceInput(start int) (tea.Model, tea.Cmd) {
+	for i := start; i < len(m.missing); i++ {
+		input := m.missing[i]
+		if !m.applies(input) {
+			continue
+		}
+		if m.useDefaults && !input.Required() {
+			_ = m.applyDefault(input)
+			continue
+		}
+		if i != m.inputIndex {
+			m.inputIndex = i
+			err := m.applyDefault(input)
+			m.initCurrentInput()
+			if err != nil {
+				m.inputErr = err
+			}
+			return m, m.startInput()
+		}
+		return m, nil
+	}
+	m.done = true
+	return m, tea.Quit
+}
+
+// updatePicker passes the message to the file picker, setting the input to
+// the path once one is selected.
+func (m *commandModel) updatePicker(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+	m.picker.Path = ""
+	m.picker, cmd = m.picker.Update(msg)
+	if m.picker.Path == "" {
+		return m, cmd
+	}
+	current := m.missing[m.inputIndex]
+	if err := m.confirmValue(current, func() error { return current.Value.Set(m.picker.Path) }); err != nil {
+		m.inputErr = err
+		return m, cmd
+	}
+	m.inputErr = nil
+	return m.advanceInput(m.inputIndex + 1)
+}
+
+// applyDefault renders the default of the input and transforms it, like the
+// defaults of inputs not prompted for.
+func (m *commandModel) applyDefault(input *inputs.Input) error {
+	if len(m.history) == 0 {
+		return nil
+	}
+	inps := m.currentSelection().Inputs()
+	if err := inps.ApplyDefault(input); err != nil {
+		return err
+	}
+	return inps.Transform(input)
+}
+
+// confirmValue sets the value of the input with set, then checks it against
+// its rules and applies its transforms. The input keeps its previous value when
+// any of them fails.
+func (m *commandModel) confirmValue(input *inputs.Input, set func() error) error {
+	if len(m.history) == 0 {
+		return set()
+	}
+	return m.currentSelection().Inputs().Confirm(input, set)
+}
+
+// applies reports whether the input's condition holds for the values given so
+// far. Conditions that fail to evaluate skip the input.
+func (m *commandModel) applies(input *inputs.Input) bool {
+	if len(m.history) == 0 {
+		return true
+	}
+	applies, _ := m.currentSelection().Inputs().Applies(input)
+	return applies
+}
+
+func (m *commandModel) hasOptionalInputs() bool {
+	for _, input := range m.missing[m.inputIndex:] {
+		if !input.Required() {
+			return true
+		}
+	}
+	return false
+}
+
+// checkbox returns the marker of a multi-select option
+func checkbox(checked bool) string {
+	if checked {
+		return "◉ "
+	}
+	return "○ "
+}
+
+// choosing reports whether the value of the input is chosen from a list,
+// rather than typed.
+func (m *commandModel) choosing(current *inputs.Input) bool {
+	return (current.Selectable() || m.isBooleanInput(current)) && !m.other
+}
+
 func (m *commandModel) isBooleanInput(current *inputs.Input) bool {
 	_, isBool := current.Value.(*inputs.BooleanValue)
 	return isBool
@@ -514,7 +972,7 @@ func (m *commandModel) isBooleanInput(current *inputs.Input) bool {
 
 func (m *commandModel) getBooleanOptions(current *inputs.Input) []inputs.InputOption {
 	if current.Selectable() {
-		return current.Options
+		return inputs.ChoiceOptions(current)
 	}
 	return []inputs.InputOption{
 		{Label: "true", Value: "true"},
@@ -1,6 +1,8 @@
 package ilc
 
 import (
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -398,7 +400,7 @@ func TestAskCommands_Mocked(t *testing.T) {
 		}
 	}
 
-	res, err := askCommands(sel, nil)
+	res, err := askCommands(sel, nil, nil, "", false)
 	assert.NoError(t, err)
 	assert.True(t, res.Runnable())
 	assert.Equal(t, "sub", res.commands[len(res.commands)-1].Name)
@@ -456,3 +458,464 @@ func TestCommandModel_CommandSelectMode_Truncation(t *testing.T) {
 
 
 
+
+func TestCommandModel_UseDefaults(t *testing.T) {
+	optional := &inputs.Input{Name: "user", Optional: true, Value: &inputs.StringValue{Value: "marty"}}
+	required := &inputs.Input{Name: "pass", Value: &inputs.StringValue{}}
+	m := &commandModel{
+		mode:       modeInputPrompt,
+		missing:    []*inputs.Input{optional, required},
+		inputIndex: 0,
+	}
+	m.initCurrentInput()
+	assert.Contains(t, m.View(), "[Ctrl+D] Use defaults")
+
+	_, _ = m.Update(tea.KeyMsg{Type: tea.KeyCtrlD})
+	assert.Equal(t, 1, m.inputIndex)
+	assert.False(t, m.done)
+	assert.NotContains(t, m.View(), "[Ctrl+D] Use defaults")
+
+	m.textInput.SetValue("secret")
+	_, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	assert.True(t, m.done)
+	assert.Equal(t, "marty", optional.Value.String())
+}
+
+func TestCommandModel_ConditionalInputs(t *testing.T) {
+	source := &inputs.Input{Name: "source", Options: inputs.InputOptions{{Label: "local", Value: "local"}, {Label: "registry", Value: "registry"}}, Value: &inputs.StringValue{}}
+	tag := &inputs.Input{Name: "tag", When: TemplateCondition(`eq .Input.source "registry"`), Value: &inputs.StringValue{Value: "latest"}}
+	name := &inputs.Input{Name: "name", Value: &inputs.StringValue{Value: "app"}}
+	fs := inputs.NewFlagSet("ilc", EnvVarPrefix)
+	fs.Var(source)
+	fs.Var(tag)
+	fs.Var(name)
+	m := &commandModel{
+		history:    []Selection{NewSelection(Command{Run: "echo", Inputs: Inputs{FlagSet: fs}})},
+		mode:       modeInputPrompt,
+		missing:    fs.Inputs(),
+		inputIndex: -1,
+	}
+	m.advanceInput(0)
+	assert.Equal(t, 0, m.inputIndex)
+
+	// Choosing local skips the tag input, going forwards and back
+	_, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	assert.Equal(t, 2, m.inputIndex)
+	assert.NotContains(t, m.View(), "tag:")
+	_, _ = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	assert.Equal(t, 0, m.inputIndex)
+
+	// Choosing registry prompts for the tag input
+	_, _ = m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	_, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	assert.Equal(t, 1, m.inputIndex)
+}
+
+func TestCommandModel_OptionsFrom(t *testing.T) {
+	source := &inputs.Input{Name: "source", Options: inputs.InputOptions{{Label: "local", Value: "local"}, {Label: "registry", Value: "registry"}}, Value: &inputs.StringValue{}}
+	image := &inputs.Input{Name: "image", OptionsFrom: CommandOptions{Command: "echo {{ .Input.source }}/app; echo {{ .Input.source }}/db"}, Value: &inputs.StringValue{}}
+	fs := inputs.NewFlagSet("ilc", EnvVarPrefix)
+	fs.Var(source)
+	fs.Var(image)
+	m := &commandModel{
+		history:    []Selection{NewSelection(Command{Run: "echo", Inputs: Inputs{FlagSet: fs}})},
+		mode:       modeInputPrompt,
+		missing:    fs.Inputs(),
+		inputIndex: -1,
+	}
+	m.advanceInput(0)
+
+	// Moving on to the image input starts loading its options
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	assert.NotNil(t, cmd)
+	assert.Equal(t, 1, m.inputIndex)
+	assert.True(t, m.loading)
+	assert.Contains(t, m.View(), "Loading options…")
+	assert.NotContains(t, m.View(), "[Enter] Confirm")
+
+	_, _ = m.Update(inputs.LoadOptions(m.currentSelection().Inputs().FlagSet, image)())
+	assert.False(t, m.loading)
+	assert.NoError(t, m.inputErr)
+	assert.Contains(t, m.View(), "local/db")
+
+	_, _ = m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	_, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	assert.True(t, m.done)
+	assert.Equal(t, "local/db", image.Value.String())
+}
+
+func TestCommandModel_DependentTemplates(t *testing.T) {
+	env := &inputs.Input{Name: "env", Options: inputs.InputOptions{{Label: "staging", Value: "staging"}, {Label: "production", Value: "production"}}, Value: &inputs.StringValue{}}
+	service := &inputs.Input{Name: "service", OptionsFrom: TemplateOptions{{Label: "api", Value: "{{ .Input.env }}-api"}}, Value: &inputs.StringValue{}}
+	replicas := &inputs.Input{Name: "replicas", DefaultFrom: TemplateDefault(`{{ if eq .Input.env "production" }}3{{ else }}1{{ end }}`), Value: &inputs.NumberValue{}}
+	fs := inputs.NewFlagSet("ilc", EnvVarPrefix)
+	fs.Var(env)
+	fs.Var(service)
+	fs.Var(replicas)
+	m := &commandModel{
+		history:    []Selection{NewSelection(Command{Run: "echo", Inputs: Inputs{FlagSet: fs}})},
+		mode:       modeInputPrompt,
+		missing:    fs.Inputs(),
+		inputIndex: -1,
+	}
+	m.advanceInput(0)
+
+	answerService := func() {
+		_, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		_, _ = m.Update(inputs.LoadOptions(m.currentSelection().Inputs().FlagSet, service)())
+		_, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	}
+
+	answerService()
+	assert.Equal(t, "staging-api", service.Value.String())
+	assert.Equal(t, 2, m.inputIndex)
+	assert.Equal(t, "1", m.textInput.Value())
+
+	// Changing an earlier answer re-renders the options and default after it
+	_, _ = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	_, _ = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	assert.Equal(t, 0, m.inputIndex)
+	_, _ = m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	answerService()
+	assert.Equal(t, "production-api", service.Value.String())
+	assert.Equal(t, "3", m.textInput.Value())
+}
+
+func TestCommandModel_MultiSelect(t *testing.T) {
+	list := &inputs.ListValue{}
+	svc := &inputs.Input{Name: "svc", Options: inputs.InputOptions{{Label: "API", Value: "api"}, {Label: "Web", Value: "web"}}, Value: list}
+	fs := inputs.NewFlagSet("ilc", EnvVarPrefix)
+	fs.Var(svc)
+	m := &commandModel{
+		history:    []Selection{NewSelection(Command{Run: "echo", Inputs: Inputs{FlagSet: fs}})},
+		mode:       modeInputPrompt,
+		missing:    fs.Inputs(),
+		inputIndex: -1,
+	}
+	m.advanceInput(0)
+	_, _ = m.Update(tea.KeyMsg{Type: tea.KeySpace})
+	assert.Contains(t, m.View(), "◉ API")
+	assert.Contains(t, m.View(), "○ Web")
+
+	_, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	assert.True(t, m.done)
+	assert.Equal(t, []string{"api"}, list.Values)
+}
+
+func TestCommandModel_PathPicker(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.Mkdir(filepath.Join(dir, "logs"), 0o755))
+	path := &inputs.PathValue{Base: dir, Kind: inputs.PathKindDir, Picker: true}
+	fs := inputs.NewFlagSet("ilc", EnvVarPrefix)
+	fs.Var(&inputs.Input{Name: "out", Value: path})
+	m := &commandModel{
+		history:    []Selection{NewSelection(Command{Run: "echo", Inputs: Inputs{FlagSet: fs}})},
+		mode:       modeInputPrompt,
+		missing:    fs.Inputs(),
+		inputIndex: -1,
+	}
+	_, cmd := m.advanceInput(0)
+	assert.True(t, m.picking)
+	_, _ = m.Update(cmd())
+	assert.Contains(t, m.View(), "logs")
+
+	// Esc goes back rather than up a directory
+	_, _ = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	assert.Equal(t, modeCommandSelect, m.mode)
+
+	m.mode = modeInputPrompt
+	m.inputIndex = -1
+	_, cmd = m.advanceInput(0)
+	_, _ = m.Update(cmd())
+	_, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	assert.True(t, m.done)
+	assert.Equal(t, filepath.Join(dir, "logs"), path.Value)
+}
+
+func TestCommandModel_Multiline(t *testing.T) {
+	message := &inputs.Input{Name: "message", Value: &inputs.StringValue{Multiline: true}}
+	fs := inputs.NewFlagSet("ilc", EnvVarPrefix)
+	fs.Var(message)
+	m := &commandModel{
+		history:    []Selection{NewSelection(Command{Run: "echo", Inputs: Inputs{FlagSet: fs}})},
+		mode:       modeInputPrompt,
+		missing:    fs.Inputs(),
+		inputIndex: -1,
+	}
+	m.advanceInput(0)
+	_, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("Fix bug")})
+	_, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	_, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("Details")})
+	assert.False(t, m.done)
+	assert.Contains(t, m.View(), "[Ctrl+E] Editor")
+
+	_, _ = m.Update(tea.KeyMsg{Type: tea.KeyCtrlS})
+	assert.True(t, m.done)
+	assert.Equal(t, "Fix bug\nDetails", message.Value.String())
+	assert.Contains(t, m.View(), "Fix bug …")
+}
+
+func TestCommandModel_Presets(t *testing.T) {
+	env := &inputs.Input{Name: "env", Value: &inputs.StringValue{}}
+	replicas := &inputs.Input{Name: "replicas", Value: &inputs.IntegerValue{}}
+	fs := inputs.NewFlagSet("ilc", EnvVarPrefix)
+	fs.Var(env)
+	fs.Var(replicas)
+	deploy := Command{
+		Name:   "deploy",
+		Run:    "echo",
+		Inputs: Inputs{FlagSet: fs},
+		Presets: Presets{
+			{Name: "prod-full", Values: map[string]string{"env": "prod", "replicas": "5"}},
+			{Name: "staging", Values: map[string]string{"env": "staging"}},
+		},
+	}
+	root := Command{Commands: SubCommands{{Command: deploy}}}
+	newModel := func() *commandModel {
+		return &commandModel{
+			history:   []Selection{NewSelection(root)},
+			mode:      modeCommandSelect,
+			askPreset: true,
+		}
+	}
+
+	t.Run("picker", func(t *testing.T) {
+		m := newModel()
+		_, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		assert.Equal(t, modePresetSelect, m.mode)
+		assert.Contains(t, m.View(), "prod-full")
+		assert.Contains(t, m.View(), "env=prod, replicas=5")
+
+		_, _ = m.Update(tea.KeyMsg{Type: tea.KeyDown})
+		_, _ = m.Update(tea.KeyMsg{Type: tea.KeyDown})
+		_, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		assert.Equal(t, modeInputPrompt, m.mode)
+		assert.Equal(t, "staging", env.Value.String())
+		assert.Equal(t, []*inputs.Input{replicas}, m.missing)
+		assert.Contains(t, m.View(), "Preset:")
+
+		_, _ = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+		assert.Equal(t, modePresetSelect, m.mode)
+		assert.Equal(t, 2, m.selectedIndex)
+
+		_, _ = m.Update(tea.KeyMsg{Type: tea.KeyUp})
+		_, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		assert.True(t, m.done)
+		assert.Equal(t, "prod", env.Value.String())
+		assert.Equal(t, "5", replicas.Value.String())
+	})
+
+	t.Run("none", func(t *testing.T) {
+		m := newModel()
+		_, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		_, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		assert.Equal(t, modeInputPrompt, m.mode)
+		assert.Equal(t, "", m.preset)
+		assert.Len(t, m.missing, 2)
+
+		_, _ = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+		_, _ = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+		assert.Equal(t, modeCommandSelect, m.mode)
+		assert.Len(t, m.history, 1)
+	})
+
+	t.Run("given", func(t *testing.T) {
+		m := newModel()
+		m.askPreset = false
+		m.preset = "prod-full"
+		_, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		assert.True(t, m.done)
+		assert.Equal(t, "prod", env.Value.String())
+	})
+}
+
+func TestCommandModel_Suggestions(t *testing.T) {
+	branch := &inputs.Input{Name: "branch", Suggestions: []string{"main"}, SuggestionsFrom: CommandOptions{Command: "echo release"}, Value: &inputs.StringValue{}}
+	fs := inputs.NewFlagSet("ilc", EnvVarPrefix)
+	fs.Var(branch)
+	m := &commandModel{
+		history:    []Selection{NewSelection(Command{Run: "echo", Inputs: Inputs{FlagSet: fs}})},
+		mode:       modeInputPrompt,
+		missing:    fs.Inputs(),
+		inputIndex: -1,
+	}
+	_, cmd := m.advanceInput(0)
+	assert.Equal(t, []string{"main"}, m.textInput.AvailableSuggestions())
+	if assert.NotNil(t, cmd) {
+		_, _ = m.Update(cmd())
+	}
+	assert.Equal(t, []string{"main", "release"}, m.textInput.AvailableSuggestions())
+
+	_, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("rel")})
+	_, _ = m.Update(tea.KeyMsg{Type: tea.KeyTab})
+	assert.Equal(t, "release", m.textInput.Value())
+}
+
+func TestCommandModel_AllowOther(t *testing.T) {
+	target := &inputs.Input{Name: "target", AllowOther: true, Options: inputs.InputOptions{{Label: "main", Value: "main"}}, Value: &inputs.StringValue{Value: "main"}}
+	fs := inputs.NewFlagSet("ilc", EnvVarPrefix)
+	fs.Var(target)
+	m := &commandModel{
+		history:    []Selection{NewSelection(Command{Run: "echo", Inputs: Inputs{FlagSet: fs}})},
+		mode:       modeInputPrompt,
+		missing:    fs.Inputs(),
+		inputIndex: -1,
+	}
+	m.advanceInput(0)
+	assert.Contains(t, m.View(), inputs.OtherLabel)
+
+	_, _ = m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	_, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	assert.True(t, m.other)
+	assert.Equal(t, "", m.textInput.Value(), "options aren't typed again")
+
+	_, _ = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	assert.False(t, m.other)
+	assert.Equal(t, modeInputPrompt, m.mode, "escape returns to the options")
+
+	_, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	_, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("fix/login")})
+	_, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	assert.True(t, m.done)
+	assert.Equal(t, "fix/login", target.Value.String())
+}
+
+func TestCommandModel_OptionEntries(t *testing.T) {
+	env := &inputs.Input{Name: "env", Value: &inputs.StringValue{}, Options: inputs.InputOptions{
+		{Label: "Production", Value: "prod", Group: "Release", Disabled: "Frozen"},
+		{Label: "Staging", Value: "staging", Group: "Release", Description: "Mirrors production"},
+		{Label: "Development", Value: "dev", Group: "Pre-release"},
+	}}
+	fs := inputs.NewFlagSet("ilc", EnvVarPrefix)
+	fs.Var(env)
+	m := &commandModel{
+		history:    []Selection{NewSelection(Command{Run: "echo", Inputs: Inputs{FlagSet: fs}})},
+		mode:       modeInputPrompt,
+		missing:    fs.Inputs(),
+		inputIndex: -1,
+	}
+	m.advanceInput(0)
+	view := m.View()
+	assert.Equal(t, 1, strings.Count(view, "Release"), "groups head their options once")
+	assert.Contains(t, view, "Pre-release")
+	assert.Contains(t, view, "Mirrors production")
+	assert.Contains(t, view, "Production    (disabled: Frozen)")
+	assert.Equal(t, 1, m.optionsIndex, "disabled options aren't chosen first")
+
+	_, _ = m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	_, _ = m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	assert.Equal(t, 1, m.optionsIndex, "disabled options are skipped")
+	_, _ = m.Update(tea.KeyMsg{Type: tea.KeyUp})
+	_, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	assert.True(t, m.done)
+	assert.Equal(t, "dev", env.Value.String())
+}
+
+func TestCommandModel_RejectedValueNotKept(t *testing.T) {
+	name := &inputs.Input{Name: "name", Optional: true, Value: &inputs.StringValue{Value: "guest"}, Rules: []inputs.Rule{
+		CommandRule{Command: `test "$ILC_VALUE" != root`, Message: "is the superuser"},
+	}}
+	fs := inputs.NewFlagSet("ilc", EnvVarPrefix)
+	fs.Var(name)
+	m := &commandModel{
+		history:    []Selection{NewSelection(Command{Run: "echo", Inputs: Inputs{FlagSet: fs}})},
+		mode:       modeInputPrompt,
+		missing:    fs.Inputs(),
+		inputIndex: -1,
+	}
+	m.advanceInput(0)
+	m.textInput.SetValue("root")
+	_, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	assert.EqualError(t, m.inputErr, "is the superuser")
+	assert.Equal(t, "guest", name.Value.String(), "rejected values aren't kept")
+
+	_, _ = m.Update(tea.KeyMsg{Type: tea.KeyCtrlD})
+	assert.True(t, m.done)
+	assert.Equal(t, "guest", name.Value.String())
+}
+
+func TestCommandModel_Rules(t *testing.T) {
+	name := &inputs.Input{Name: "name", Value: &inputs.StringValue{}, Rules: []inputs.Rule{
+		TemplateRule{Template: `ne .Value "admin"`, Message: "is reserved"},
+		CommandRule{Command: `test "$ILC_VALUE" != root`, Message: "is the superuser"},
+	}}
+	fs := inputs.NewFlagSet("ilc", EnvVarPrefix)
+	fs.Var(name)
+	m := &commandModel{
+		history:    []Selection{NewSelection(Command{Run: "echo", Inputs: Inputs{FlagSet: fs}})},
+		mode:       modeInputPrompt,
+		missing:    fs.Inputs(),
+		inputIndex: -1,
+	}
+	m.advanceInput(0)
+	_, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("admin")})
+	assert.EqualError(t, m.inputErr, "is reserved")
+	assert.Equal(t, "", name.Value.String())
+
+	m.textInput.SetValue("")
+	_, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("root")})
+	assert.NoError(t, m.inputErr, "command rules aren't run as the value is typed")
+	_, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	assert.False(t, m.done)
+	assert.EqualError(t, m.inputErr, "is the superuser")
+
+	m.textInput.SetValue("web")
+	_, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	assert.True(t, m.done)
+	assert.Equal(t, "web", name.Value.String())
+}
+
+func TestCommandModel_TransformOnce(t *testing.T) {
+	host := &inputs.Input{Name: "host", Value: &inputs.StringValue{}, Transforms: []inputs.Transform{
+		TemplateTransform("{{ .Value }}.example.com"),
+	}}
+	port := &inputs.Input{Name: "port", Value: &inputs.IntegerValue{}}
+	fs := inputs.NewFlagSet("ilc", EnvVarPrefix)
+	fs.Var(host)
+	fs.Var(port)
+	m := &commandModel{
+		history:    []Selection{NewSelection(Command{Run: "echo", Inputs: Inputs{FlagSet: fs}})},
+		mode:       modeInputPrompt,
+		missing:    fs.Inputs(),
+		inputIndex: -1,
+	}
+	m.advanceInput(0)
+	_, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("h")})
+	_, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	assert.Equal(t, "h.example.com", host.Value.String())
+
+	_, _ = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	assert.Equal(t, 0, m.inputIndex)
+	assert.Equal(t, "h", m.textInput.Value(), "stepping back shows the value as given")
+	_, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	assert.Equal(t, "h.example.com", host.Value.String(), "confirming again doesn't transform twice")
+
+	host.DefaultFrom = RememberedDefault("r.example.com")
+	host.Recorded = []string{"r.example.com"}
+	m.inputIndex = -1
+	m.advanceInput(0)
+	assert.Equal(t, "r.example.com", m.textInput.Value(), "remembered values are prefilled as they are")
+	_, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	assert.Equal(t, "r.example.com", host.Value.String(), "remembered values aren't transformed again")
+}
+
+func TestCommandModel_Transform(t *testing.T) {
+	name := &inputs.Input{Name: "name", Value: &inputs.StringValue{}, Transforms: []inputs.Transform{
+		StringTransform("trim"),
+		StringTransform("slugify"),
+	}}
+	fs := inputs.NewFlagSet("ilc", EnvVarPrefix)
+	fs.Var(name)
+	m := &commandModel{
+		history:    []Selection{NewSelection(Command{Run: "echo", Inputs: Inputs{FlagSet: fs}})},
+		mode:       modeInputPrompt,
+		missing:    fs.Inputs(),
+		inputIndex: -1,
+	}
+	m.advanceInput(0)
+	_, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(" My Service ")})
+	_, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	assert.True(t, m.done)
+	assert.Equal(t, "my-service", name.Value.String())
+	assert.Contains(t, m.View(), "my-service")
+}
@@ -5,15 +5,20 @@ import (
 	"flag"
 	"fmt"
 	"io"
+	"maps"
 	"os"
 	"path/filepath"
 	"strings"
+
+	"github.com/evilmarty/ilc/internal/inputs"
 )
 
 const (
-	EnvVarPrefix = "ILC_INPUT_"
-	EnvHistFile  = "ILC_HISTFILE"
-	ReplayPrefix = "!"
+	EnvVarPrefix  = "ILC_INPUT_"
+	EnvHistFile   = "ILC_HISTFILE"
+	EnvValue      = "ILC_VALUE"
+	ReplayPrefix  = "!"
+	ArgsSeparator = "--"
 )
 
 var (
@@ -21,6 +26,8 @@ var (
 	ErrMissingArguments  = errors.New("no arguments given")
 	ErrInvalidCommand    = errors.New("invalid command")
 	ErrInvalidReplay     = errors.New("invalid replay command")
+	ErrAmbiguousArgument = errors.New("ambiguous argument")
+	ErrUnknownPreset     = errors.New("unknown preset")
 )
 
 var exitFunc = os.Exit
@@ -39,7 +46,12 @@ type Runner struct {
 	Args           []string
 	Entrypoint     []string
 	NonInteractive bool
+	AcceptDefaults bool
 	ValidateConfig bool
+	InputsFile     string
+	InputsJSON     string
+	DumpInputs     string
+	Preset         string
 	ConfigPath     string
 	Config         *Config
 	HistoryFile    string
@@ -99,7 +111,12 @@ func (r *Runner) flagSet() *flag.FlagSet {
 	})
 	fs.BoolVar(&r.Debug, "debug", false, "Print debug information")
 	fs.BoolVar(&r.NonInteractive, "non-interactive", false, "Disable interactivity")
+	fs.BoolVar(&r.AcceptDefaults, "accept-defaults", false, "Use defaults for optional inputs without prompting")
 	fs.BoolVar(&r.ValidateConfig, "validate", false, "Validate configuration")
+	fs.StringVar(&r.InputsFile, "inputs-file", "", "Read input values from a YAML or JSON file")
+	fs.StringVar(&r.InputsJSON, "inputs-json", "", "Read input values from JSON, or stdin when -")
+	fs.StringVar(&r.Preset, "preset", "", "Use the input values of a preset")
+	fs.StringVar(&r.DumpInputs, "dump-inputs", "", "Write the input values to a YAML or JSON file, or stdout when -")
 	return fs
 }
 
@@ -136,13 +153,41 @@ func (r *Runner) Parse(args []string) error {
 
 func (r *Runner) run() error {
 	var err error
-	logger.Printf("Running with arguments: %s\n", strings.Join(r.Args, " "))
-	selection := r.Config.Select(r.Args)
+	r.useHistory()
+	selection, err := r.Config.Select(r.Args)
+	if err != nil {
+		return err
+	}
+	logger.Printf("Running with arguments: %s\n", strings.Join(selection.RedactedArgs(), " "))
+	given, err := r.inputValues()
+	if err != nil {
+		return err
+	}
 	inps := selection.Inputs()
-	missing, err := inps.ParseEnvAndArgs(selection.Args, r.Env)
+	values := given
+	if selection.Runnable() {
+		if values, err = selection.PresetValues(r.Preset, given); err != nil {
+			return err
+		}
+		if err := CheckValues(inps, values); err != nil {
+			return err
+		}
+	}
+	missing, err := inps.ParseValuesEnvAndArgs(values, selection.InputArgs(), r.Env)
 	if err != nil {
 		return err
 	}
+	if extra := inps.Args(); selection.Runnable() && len(extra) > 0 {
+		return fmt.Errorf("unexpected arguments: %s", strings.Join(extra, " "))
+	}
+	if r.NonInteractive || r.AcceptDefaults {
+		missing = inputs.Required(missing)
+	}
+	if r.NonInteractive {
+		if missing, err = inps.Applicable(missing); err != nil {
+			return err
+		}
+	}
 
 	if !selection.Runnable() || len(missing) > 0 {
 		if r.NonInteractive {
@@ -155,23 +200,43 @@ func (r *Runner) run() error {
 			}
 			return fmt.Errorf("missing inputs: %s", strings.Join(missingNames, ", "))
 		}
-		selection, err = askCommands(selection, r.Env)
+		selection, err = askCommands(selection, r.Env, given, r.Preset, r.AcceptDefaults)
 		if err != nil {
 			return err
 		}
 		inps = selection.Inputs()
+		if err := CheckValues(inps, given); err != nil {
+			return err
+		}
 	}
 
-	values := inps.Values()
-	data := NewTemplateData(values, r.Env)
+	scriptArgs := selection.ScriptArgs()
+	if args := selection.CommandArgs(); args != nil {
+		if err := args.Check(scriptArgs); err != nil {
+			return err
+		}
+	}
+
+	if _, err := inps.Active(); err != nil {
+		return err
+	}
+
+	if r.DumpInputs != "" {
+		if err := r.dumpInputs(inps); err != nil {
+			return fmt.Errorf("failed to dump inputs: %w", err)
+		}
+	}
+
+	data := NewTemplateData(inps.Values(), r.Env)
+	data.Args = scriptArgs
 	cmd, err := selection.Cmd(data, r.Env)
 	if err != nil {
 		return fmt.Errorf("failed generating script: %v", err)
 	}
 
 	// Clean up temporary script file after the runner finishes
-	if len(cmd.Args) > 0 {
-		scriptFile := cmd.Args[len(cmd.Args)-1]
+	if i := len(cmd.Args) - len(scriptArgs) - 1; i >= 0 {
+		scriptFile := cmd.Args[i]
 		defer os.Remove(scriptFile)
 	}
 
@@ -227,7 +292,7 @@ func (r *Runner) Run() error {
 	}
 
 	if errors.Is(err, flag.ErrHelp) {
-		selection := r.Config.Select(r.Args)
+		selection, _ := r.Config.Select(r.Args)
 		output := r.Output
 		if output == nil {
 			output = os.Stderr
@@ -244,6 +309,126 @@ func (r *Runner) Run() error {
 	return err
 }
 
+// inputValues reads the input values of the inputs file, then those of the
+// JSON, which is read from stdin when it is -.
+func (r *Runner) inputValues() (map[string]string, error) {
+	values := map[string]string{}
+	if r.InputsFile != "" {
+		file, err := os.Open(r.InputsFile)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+		fileValues, err := ReadValues(file)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", r.InputsFile, err)
+		}
+		maps.Copy(values, fileValues)
+	}
+	if r.InputsJSON != "" {
+		var reader io.Reader = strings.NewReader(r.InputsJSON)
+		if r.InputsJSON == StdioPath {
+			reader = r.Stdin
+			if reader == nil {
+				reader = os.Stdin
+			}
+		}
+		jsonValues, err := ReadValues(reader)
+		if err != nil {
+			return nil, err
+		}
+		maps.Copy(values, jsonValues)
+	}
+	return values, nil
+}
+
+// dumpInputs writes the values of the inputs to the dump file, or stdout.
+func (r *Runner) dumpInputs(inps Inputs) error {
+	if r.DumpInputs == StdioPath {
+		stdout := r.Stdout
+		if stdout == nil {
+			stdout = os.Stdout
+		}
+		return WriteValues(stdout, r.DumpInputs, inps.ToValues())
+	}
+	file, err := os.Create(r.DumpInputs)
+	if err != nil {
+		return err
+	}
+	if err := WriteValues(file, r.DumpInputs, inps.ToValues()); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// useHistory makes the values of remembered inputs in the latest history
+// entry of their command their defaults. Inputs shared by several commands,
+// like those of a parent, take their value from the latest entry of any. When
+// inputs may be prompted for, the values previously given to typed inputs are
+// suggested too, latest first.
+func (r *Runner) useHistory() {
+	if r.NonInteractive && !remembers(NewSelection(Command(*r.Config))) {
+		return
+	}
+	history, err := r.getHistoryStore().Load(r.HistoryFile)
+	if err != nil {
+		logger.Printf("Failed to load history for inputs: %v\n", err)
+		return
+	}
+	entries := history.Records[r.ConfigPath]
+	seeded := make(map[*inputs.Input]bool)
+	suggestions := make(map[*inputs.Input][]string)
+	for i := len(entries) - 1; i >= 0; i-- {
+		selection, err := r.Config.Select(entries[i])
+		if err != nil || !selection.Runnable() {
+			continue
+		}
+		inps := selection.Inputs()
+		values := inps.ArgValues(selection.InputArgs())
+		if !r.NonInteractive {
+			for _, input := range inps.Inputs() {
+				if value := values[input.Name]; value != "" && suggestable(input) {
+					suggestions[input] = append(suggestions[input], value)
+					input.Recorded = append(input.Recorded, value)
+				}
+			}
+		}
+		for _, input := range selection.Remembered() {
+			// Values no longer valid, ie. of removed options, are forgotten
+			if value, found := values[input.Name]; found && !seeded[input] && input.Check(value) == nil {
+				input.DefaultFrom = RememberedDefault(value)
+				input.Recorded = append(input.Recorded, value)
+				seeded[input] = true
+			}
+		}
+	}
+	for input, previous := range suggestions {
+		input.Suggestions = inputs.Unique(append(previous, input.Suggestions...))
+	}
+}
+
+// suggestable reports whether the input is typed as a line of text, which
+// suggestions can complete.
+func suggestable(input *inputs.Input) bool {
+	_, isString := input.Value.(*inputs.StringValue)
+	return isString && !input.Secret && !input.Multiline() && !input.Selectable()
+}
+
+// remembers reports whether the selected command, or any of its subcommands,
+// has remembered inputs.
+func remembers(selection Selection) bool {
+	if len(selection.Remembered()) > 0 {
+		return true
+	}
+	for _, subcommand := range selection.commands[len(selection.commands)-1].Commands {
+		if remembers(selection.SelectCommand(subcommand.Command, nil)) {
+			return true
+		}
+	}
+	return false
+}
+
 func (r *Runner) replay() error {
 	store := r.getHistoryStore()
 	history, err := store.Load(r.HistoryFile)
@@ -257,6 +442,16 @@ func (r *Runner) replay() error {
 	if args, found := history.Lookup(r.ConfigPath, r.Args); found {
 		r.Args = args
 		logger.Printf("Replaying using arguments: %s\n", strings.Join(r.Args, " "))
+		if selection, err := r.Config.Select(args); err == nil {
+			// The values in history are already transformed
+			inps := selection.Inputs()
+			values := inps.ArgValues(selection.InputArgs())
+			for _, input := range inps.Inputs() {
+				if value, found := values[input.Name]; found {
+					input.Recorded = append(input.Recorded, value)
+				}
+			}
+		}
 		return r.run()
 	} else {
 		return ErrInvalidReplay
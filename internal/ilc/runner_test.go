@@ -2,7 +2,10 @@ package ilc
 
 import (
 	"bytes"
+	"io"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -345,3 +348,937 @@ func TestRunner_UsageFlagSet(t *testing.T) {
 
 
 
+
+func TestRunner_RunScriptArgs(t *testing.T) {
+	content := `
+commands:
+  greet:
+    args:
+      max: 2
+    run: echo "{{ len .Args }}" "$@"
+`
+	tempFile, err := os.CreateTemp("", "ilc-test-*.yml")
+	assert.NoError(t, err)
+	defer os.Remove(tempFile.Name())
+
+	_, err = tempFile.Write([]byte(content))
+	assert.NoError(t, err)
+	tempFile.Close()
+
+	t.Run("passes arguments through", func(t *testing.T) {
+		mockStore := &MockHistoryStore{
+			History: &History{Records: make(map[string][][]string)},
+		}
+		var outBuf bytes.Buffer
+		r := Runner{
+			Name:         "ILC",
+			Stdout:       &outBuf,
+			Stderr:       &outBuf,
+			HistoryStore: mockStore,
+		}
+		err := r.Parse([]string{"ilc", "-non-interactive", tempFile.Name(), "greet", "--", "foo", "-bar"})
+		assert.NoError(t, err)
+
+		err = r.Run()
+		assert.NoError(t, err)
+		assert.Equal(t, "2 foo -bar\n", outBuf.String())
+		assert.Subset(t, mockStore.Saved[0].Records[tempFile.Name()][0], []string{"greet", "--", "foo", "-bar"})
+	})
+
+	t.Run("rejects too many arguments", func(t *testing.T) {
+		r := Runner{Name: "ILC", HistoryStore: &MockHistoryStore{}}
+		err := r.Parse([]string{"ilc", "-non-interactive", tempFile.Name(), "greet", "--", "a", "b", "c"})
+		assert.NoError(t, err)
+
+		err = r.Run()
+		assert.ErrorContains(t, err, "expected at most 2 arguments, got 3")
+	})
+}
+
+func TestRunner_RunPositionalInputs(t *testing.T) {
+	content := `
+commands:
+  deploy:
+    inputs:
+      env:
+        positional: 1
+      service:
+        positional: 2
+    run: echo "{{ .Input.env }} {{ .Input.service }}"
+`
+	tempFile, err := os.CreateTemp("", "ilc-test-*.yml")
+	assert.NoError(t, err)
+	defer os.Remove(tempFile.Name())
+
+	_, err = tempFile.Write([]byte(content))
+	assert.NoError(t, err)
+	tempFile.Close()
+
+	t.Run("fills from bare arguments", func(t *testing.T) {
+		var outBuf bytes.Buffer
+		r := Runner{
+			Name:         "ILC",
+			Stdout:       &outBuf,
+			Stderr:       &outBuf,
+			HistoryStore: &MockHistoryStore{History: &History{Records: make(map[string][][]string)}},
+		}
+		err := r.Parse([]string{"ilc", "-non-interactive", tempFile.Name(), "deploy", "prod", "api"})
+		assert.NoError(t, err)
+
+		err = r.Run()
+		assert.NoError(t, err)
+		assert.Equal(t, "prod api\n", outBuf.String())
+	})
+
+	t.Run("mixed with flags", func(t *testing.T) {
+		var outBuf bytes.Buffer
+		r := Runner{
+			Name:         "ILC",
+			Stdout:       &outBuf,
+			Stderr:       &outBuf,
+			HistoryStore: &MockHistoryStore{History: &History{Records: make(map[string][][]string)}},
+		}
+		err := r.Parse([]string{"ilc", "-non-interactive", tempFile.Name(), "deploy", "-env", "prod", "api"})
+		assert.NoError(t, err)
+
+		err = r.Run()
+		assert.NoError(t, err)
+		assert.Equal(t, "prod api\n", outBuf.String())
+	})
+
+	t.Run("rejects unexpected arguments", func(t *testing.T) {
+		r := Runner{Name: "ILC", HistoryStore: &MockHistoryStore{}}
+		err := r.Parse([]string{"ilc", "-non-interactive", tempFile.Name(), "deploy", "prod", "api", "extra"})
+		assert.NoError(t, err)
+
+		err = r.Run()
+		assert.ErrorContains(t, err, "unexpected arguments: extra")
+	})
+}
+
+func TestRunner_RunNonInteractiveDefaults(t *testing.T) {
+	content := `
+inputs:
+  name:
+    default: World
+commands:
+  greet:
+    run: echo "Hello {{ .Input.name }}"
+`
+	tempFile, err := os.CreateTemp("", "ilc-test-*.yml")
+	assert.NoError(t, err)
+	defer os.Remove(tempFile.Name())
+
+	_, err = tempFile.Write([]byte(content))
+	assert.NoError(t, err)
+	tempFile.Close()
+
+	var outBuf bytes.Buffer
+	r := Runner{
+		Name:         "ILC",
+		Stdout:       &outBuf,
+		Stderr:       &outBuf,
+		HistoryStore: &MockHistoryStore{History: &History{Records: make(map[string][][]string)}},
+	}
+	err = r.Parse([]string{"ilc", "-non-interactive", tempFile.Name(), "greet"})
+	assert.NoError(t, err)
+
+	err = r.Run()
+	assert.NoError(t, err)
+	assert.Equal(t, "Hello World\n", outBuf.String())
+}
+
+func TestRunner_RunConditionalInputs(t *testing.T) {
+	content := `
+inputs:
+  source:
+    options: [local, registry]
+  tag:
+    when: eq .Input.source "registry"
+commands:
+  pull:
+    run: echo "$ILC_INPUT_SOURCE ${ILC_INPUT_TAG-none}"
+`
+	tempFile, err := os.CreateTemp("", "ilc-test-*.yml")
+	assert.NoError(t, err)
+	defer os.Remove(tempFile.Name())
+
+	_, err = tempFile.Write([]byte(content))
+	assert.NoError(t, err)
+	tempFile.Close()
+
+	t.Run("skipped input is not required", func(t *testing.T) {
+		var outBuf bytes.Buffer
+		r := Runner{
+			Name:         "ILC",
+			Stdout:       &outBuf,
+			Stderr:       &outBuf,
+			HistoryStore: &MockHistoryStore{History: &History{Records: make(map[string][][]string)}},
+		}
+		err := r.Parse([]string{"ilc", "-non-interactive", tempFile.Name(), "pull", "-source", "local"})
+		assert.NoError(t, err)
+		assert.NoError(t, r.Run())
+		assert.Equal(t, "local none\n", outBuf.String())
+	})
+
+	t.Run("applicable input is required", func(t *testing.T) {
+		r := Runner{Name: "ILC", HistoryStore: &MockHistoryStore{}}
+		err := r.Parse([]string{"ilc", "-non-interactive", tempFile.Name(), "pull", "-source", "registry"})
+		assert.NoError(t, err)
+		assert.ErrorContains(t, r.Run(), "missing inputs: tag")
+	})
+}
+
+func TestRunner_RunOptionsFrom(t *testing.T) {
+	content := `
+inputs:
+  env:
+    options_from: printf 'staging\nproduction\n'
+commands:
+  deploy:
+    run: echo "$ILC_INPUT_ENV"
+`
+	tempFile, err := os.CreateTemp("", "ilc-test-*.yml")
+	assert.NoError(t, err)
+	defer os.Remove(tempFile.Name())
+
+	_, err = tempFile.Write([]byte(content))
+	assert.NoError(t, err)
+	tempFile.Close()
+
+	t.Run("generated value", func(t *testing.T) {
+		var outBuf bytes.Buffer
+		r := Runner{
+			Name:         "ILC",
+			Stdout:       &outBuf,
+			Stderr:       &outBuf,
+			HistoryStore: &MockHistoryStore{History: &History{Records: make(map[string][][]string)}},
+		}
+		err := r.Parse([]string{"ilc", "-non-interactive", tempFile.Name(), "deploy", "-env", "staging"})
+		assert.NoError(t, err)
+		assert.NoError(t, r.Run())
+		assert.Equal(t, "staging\n", outBuf.String())
+	})
+
+	t.Run("unknown value", func(t *testing.T) {
+		r := Runner{Name: "ILC", HistoryStore: &MockHistoryStore{}}
+		err := r.Parse([]string{"ilc", "-non-interactive", tempFile.Name(), "deploy", "-env", "dev"})
+		assert.NoError(t, err)
+		assert.ErrorContains(t, r.Run(), `invalid value "dev" for input env: not one of the options: staging, production`)
+	})
+}
+
+func TestRunner_RunEnforcedOptions(t *testing.T) {
+	content := `
+inputs:
+  month:
+    type: number
+    options:
+      January: 1
+      February: 2
+commands:
+  report:
+    run: echo "$ILC_INPUT_MONTH"
+`
+	tempFile, err := os.CreateTemp("", "ilc-test-*.yml")
+	assert.NoError(t, err)
+	defer os.Remove(tempFile.Name())
+
+	_, err = tempFile.Write([]byte(content))
+	assert.NoError(t, err)
+	tempFile.Close()
+
+	t.Run("label", func(t *testing.T) {
+		var outBuf bytes.Buffer
+		r := Runner{
+			Name:         "ILC",
+			Stdout:       &outBuf,
+			Stderr:       &outBuf,
+			HistoryStore: &MockHistoryStore{History: &History{Records: make(map[string][][]string)}},
+		}
+		err := r.Parse([]string{"ilc", "-non-interactive", tempFile.Name(), "report", "-month", "February"})
+		assert.NoError(t, err)
+		assert.NoError(t, r.Run())
+		assert.Equal(t, "2\n", outBuf.String())
+	})
+
+	t.Run("misspelt label", func(t *testing.T) {
+		r := Runner{Name: "ILC", HistoryStore: &MockHistoryStore{}}
+		err := r.Parse([]string{"ilc", "-non-interactive", tempFile.Name(), "report", "-month", "Febuary"})
+		assert.NoError(t, err)
+		assert.EqualError(t, r.Run(), `invalid value "Febuary" for input -month: not one of the options, did you mean "February"?`)
+	})
+
+	t.Run("environment variable", func(t *testing.T) {
+		r := Runner{Name: "ILC", Env: map[string]string{"ILC_INPUT_MONTH": "13"}, HistoryStore: &MockHistoryStore{}}
+		err := r.Parse([]string{"ilc", "-non-interactive", tempFile.Name(), "report"})
+		assert.NoError(t, err)
+		assert.EqualError(t, r.Run(), "invalid environment variable ILC_INPUT_MONTH: not one of the options: 1, 2")
+	})
+}
+
+func TestRunner_RunListInputs(t *testing.T) {
+	content := `
+inputs:
+  services:
+    type: list
+    options: [api, web, db]
+    separator: " "
+commands:
+  restart:
+    run: |
+      echo "$ILC_INPUT_SERVICES"
+      echo "{{ range .Input.services }}[{{ . }}]{{ end }}"
+`
+	tempFile, err := os.CreateTemp("", "ilc-test-*.yml")
+	assert.NoError(t, err)
+	defer os.Remove(tempFile.Name())
+
+	_, err = tempFile.Write([]byte(content))
+	assert.NoError(t, err)
+	tempFile.Close()
+
+	var outBuf bytes.Buffer
+	r := Runner{
+		Name:         "ILC",
+		Stdout:       &outBuf,
+		Stderr:       &outBuf,
+		HistoryStore: &MockHistoryStore{History: &History{Records: make(map[string][][]string)}},
+	}
+	err = r.Parse([]string{"ilc", "-non-interactive", tempFile.Name(), "restart", "-services", "api", "-services", "db"})
+	assert.NoError(t, err)
+	assert.NoError(t, r.Run())
+	assert.Equal(t, "api db\n[api][db]\n", outBuf.String())
+}
+
+func TestRunner_RunSecretInputs(t *testing.T) {
+	content := `
+inputs:
+  token:
+    secret: true
+  user: string
+commands:
+  login:
+    run: echo "$ILC_INPUT_USER $ILC_INPUT_TOKEN"
+`
+	tempFile, err := os.CreateTemp("", "ilc-test-*.yml")
+	assert.NoError(t, err)
+	defer os.Remove(tempFile.Name())
+
+	_, err = tempFile.Write([]byte(content))
+	assert.NoError(t, err)
+	tempFile.Close()
+
+	var outBuf, logBuf bytes.Buffer
+	logger.SetOutput(&logBuf)
+	defer logger.SetOutput(io.Discard)
+	mockStore := &MockHistoryStore{History: &History{Records: make(map[string][][]string)}}
+	r := Runner{
+		Name:         "ILC",
+		Stdout:       &outBuf,
+		Stderr:       &outBuf,
+		HistoryStore: mockStore,
+	}
+	err = r.Parse([]string{"ilc", "-non-interactive", tempFile.Name(), "login", "-user", "bob", "-token", "s3cret"})
+	assert.NoError(t, err)
+	assert.NoError(t, r.Run())
+	assert.Equal(t, "bob s3cret\n", outBuf.String())
+
+	// The secret is neither logged nor recorded to history
+	assert.Contains(t, logBuf.String(), "Running with arguments: login -user bob -token ******")
+	assert.NotContains(t, logBuf.String(), "s3cret")
+	if assert.Len(t, mockStore.Saved, 1) {
+		record := mockStore.Saved[0].Records[tempFile.Name()][0]
+		assert.Contains(t, record, "--user=bob")
+		assert.NotContains(t, strings.Join(record, " "), "s3cret")
+	}
+}
+
+func TestRunner_RunIntegerInputs(t *testing.T) {
+	content := `
+inputs:
+  type:
+    type: integer
+    default: 0
+commands:
+  icon:
+    run: echo "{{ if eq .Input.type 0 }}reset{{ else }}set {{ .Input.type }}{{ end }}"
+`
+	tempFile, err := os.CreateTemp("", "ilc-test-*.yml")
+	assert.NoError(t, err)
+	defer os.Remove(tempFile.Name())
+
+	_, err = tempFile.Write([]byte(content))
+	assert.NoError(t, err)
+	tempFile.Close()
+
+	for args, expected := range map[string]string{"": "reset\n", "3": "set 3\n"} {
+		var outBuf bytes.Buffer
+		r := Runner{
+			Name:         "ILC",
+			Stdout:       &outBuf,
+			Stderr:       &outBuf,
+			HistoryStore: &MockHistoryStore{History: &History{Records: make(map[string][][]string)}},
+		}
+		cliArgs := []string{"ilc", "-non-interactive", tempFile.Name(), "icon"}
+		if args != "" {
+			cliArgs = append(cliArgs, "-type", args)
+		}
+		assert.NoError(t, r.Parse(cliArgs))
+		assert.NoError(t, r.Run())
+		assert.Equal(t, expected, outBuf.String())
+	}
+}
+
+func TestRunner_RunPathInputs(t *testing.T) {
+	content := `
+inputs:
+  file:
+    type: path
+    kind: file
+    must_exist: true
+    base: .
+commands:
+  cat:
+    run: echo {{ .Input.file }}
+`
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "ilc.yml")
+	assert.NoError(t, os.WriteFile(configPath, []byte(content), 0o644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "app.yml"), nil, 0o644))
+
+	newRunner := func(out io.Writer) Runner {
+		return Runner{
+			Name:         "ILC",
+			Stdout:       out,
+			Stderr:       out,
+			HistoryStore: &MockHistoryStore{History: &History{Records: make(map[string][][]string)}},
+		}
+	}
+
+	var outBuf bytes.Buffer
+	r := newRunner(&outBuf)
+	assert.NoError(t, r.Parse([]string{"ilc", "-non-interactive", configPath, "cat", "-file", "app.yml"}))
+	assert.NoError(t, r.Run())
+	assert.Equal(t, filepath.Join(dir, "app.yml")+"\n", outBuf.String())
+
+	r = newRunner(io.Discard)
+	assert.NoError(t, r.Parse([]string{"ilc", "-non-interactive", configPath, "cat", "-file", "missing.yml"}))
+	assert.ErrorContains(t, r.Run(), "missing.yml does not exist")
+}
+
+func TestRunner_RunDateInputs(t *testing.T) {
+	content := `
+inputs:
+  release:
+    type: date
+  timeout:
+    type: duration
+    default: 1d
+commands:
+  tag:
+    run: echo {{ .Input.release.Format "Jan 2 2006" }} {{ .Input.timeout.Hours }} $ILC_INPUT_RELEASE
+`
+	tempFile, err := os.CreateTemp("", "ilc-test-*.yml")
+	assert.NoError(t, err)
+	defer os.Remove(tempFile.Name())
+
+	_, err = tempFile.Write([]byte(content))
+	assert.NoError(t, err)
+	tempFile.Close()
+
+	var outBuf bytes.Buffer
+	r := Runner{
+		Name:         "ILC",
+		Stdout:       &outBuf,
+		Stderr:       &outBuf,
+		HistoryStore: &MockHistoryStore{History: &History{Records: make(map[string][][]string)}},
+	}
+	assert.NoError(t, r.Parse([]string{"ilc", "-non-interactive", tempFile.Name(), "tag", "-release", "2024-05-01"}))
+	assert.NoError(t, r.Run())
+	assert.Equal(t, "May 1 2024 24 2024-05-01\n", outBuf.String())
+}
+
+func TestRunner_RunMultilineInputs(t *testing.T) {
+	content := `
+inputs:
+  message:
+    multiline: true
+commands:
+  commit:
+    run: printf '%s\n' "$ILC_INPUT_MESSAGE"
+`
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "ilc.yml")
+	assert.NoError(t, os.WriteFile(configPath, []byte(content), 0o644))
+	messagePath := filepath.Join(dir, "message.txt")
+	assert.NoError(t, os.WriteFile(messagePath, []byte("Fix bug\n\nDetails\n"), 0o644))
+
+	var outBuf bytes.Buffer
+	r := Runner{
+		Name:         "ILC",
+		Stdout:       &outBuf,
+		Stderr:       &outBuf,
+		HistoryStore: &MockHistoryStore{History: &History{Records: make(map[string][][]string)}},
+	}
+	assert.NoError(t, r.Parse([]string{"ilc", "-non-interactive", configPath, "commit", "-message", "@" + messagePath}))
+	assert.NoError(t, r.Run())
+	assert.Equal(t, "Fix bug\n\nDetails\n", outBuf.String())
+}
+
+func TestRunner_RunPreset(t *testing.T) {
+	content := `
+inputs:
+  env:
+    options: [staging, prod]
+presets:
+  staging:
+    env: staging
+  prod:
+    env: prod
+    region: eu
+    canary: false
+commands:
+  deploy:
+    inputs:
+      canary:
+        type: boolean
+    presets:
+      prod-canary:
+        env: prod
+        canary: true
+    run: echo "$ILC_INPUT_ENV $ILC_INPUT_CANARY"
+  status:
+    inputs:
+      region:
+        options: [eu, us]
+    run: echo "$ILC_INPUT_REGION"
+`
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "ilc.yml")
+	assert.NoError(t, os.WriteFile(configPath, []byte(content), 0o644))
+
+	run := func(args ...string) (string, error) {
+		var outBuf bytes.Buffer
+		r := Runner{
+			Name:         "ILC",
+			Stdout:       &outBuf,
+			Stderr:       &outBuf,
+			HistoryStore: &MockHistoryStore{History: &History{Records: make(map[string][][]string)}},
+		}
+		if err := r.Parse(append([]string{"ilc", "-non-interactive"}, args...)); err != nil {
+			return "", err
+		}
+		err := r.Run()
+		return outBuf.String(), err
+	}
+
+	t.Run("preset", func(t *testing.T) {
+		out, err := run("-preset", "prod-canary", configPath, "deploy")
+		assert.NoError(t, err)
+		assert.Equal(t, "prod true\n", out)
+	})
+
+	t.Run("inherited preset", func(t *testing.T) {
+		out, err := run("-preset", "staging", configPath, "deploy", "-canary")
+		assert.NoError(t, err)
+		assert.Equal(t, "staging true\n", out)
+	})
+
+	t.Run("overridden", func(t *testing.T) {
+		out, err := run("-preset", "prod-canary", configPath, "deploy", "-env", "staging")
+		assert.NoError(t, err)
+		assert.Equal(t, "staging true\n", out)
+	})
+
+	t.Run("unknown preset", func(t *testing.T) {
+		_, err := run("-preset", "qa", configPath, "deploy")
+		assert.EqualError(t, err, "unknown preset: qa")
+	})
+
+	t.Run("parent preset in sibling subcommand", func(t *testing.T) {
+		out, err := run("-preset", "prod", configPath, "status")
+		assert.NoError(t, err)
+		assert.Equal(t, "eu\n", out)
+		out, err = run("-preset", "prod", configPath, "deploy")
+		assert.NoError(t, err)
+		assert.Equal(t, "prod false\n", out)
+	})
+}
+
+func TestRunner_RunEnvPrefix(t *testing.T) {
+	content := `
+env_prefix: ""
+inputs:
+  aws_region: string
+commands:
+  build:
+    inputs:
+      tag:
+        env: IMAGE_TAG
+      platform: string
+    run: echo "$AWS_REGION $IMAGE_TAG $PLATFORM"
+  deploy:
+    env_prefix: DEPLOY_
+    inputs:
+      env: string
+    run: echo "$AWS_REGION $DEPLOY_ENV"
+`
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "ilc.yml")
+	assert.NoError(t, os.WriteFile(configPath, []byte(content), 0o644))
+
+	run := func(env map[string]string, args ...string) (string, error) {
+		var outBuf bytes.Buffer
+		r := Runner{
+			Name:         "ILC",
+			Env:          env,
+			Stdout:       &outBuf,
+			Stderr:       &outBuf,
+			HistoryStore: &MockHistoryStore{History: &History{Records: make(map[string][][]string)}},
+		}
+		if err := r.Parse(append([]string{"ilc", "-non-interactive"}, args...)); err != nil {
+			return "", err
+		}
+		err := r.Run()
+		return outBuf.String(), err
+	}
+
+	out, err := run(map[string]string{"AWS_REGION": "eu", "IMAGE_TAG": "v1", "PLATFORM": "arm"}, configPath, "build")
+	assert.NoError(t, err)
+	assert.Equal(t, "eu v1 arm\n", out)
+
+	out, err = run(map[string]string{"AWS_REGION": "eu", "DEPLOY_ENV": "prod", "ILC_INPUT_ENV": "staging"}, configPath, "deploy")
+	assert.NoError(t, err)
+	assert.Equal(t, "eu prod\n", out)
+
+	out, err = run(nil, configPath, "build", "-aws_region", "us", "-tag", "v2", "-platform", "amd")
+	assert.NoError(t, err)
+	assert.Equal(t, "us v2 amd\n", out)
+}
+
+func TestRunner_RunValidateRules(t *testing.T) {
+	content := `
+inputs:
+  start:
+    type: date
+  end:
+    type: date
+    validate:
+      - template: '{{ .Value.After .Input.start }}'
+        message: must be after start
+  name:
+    validate:
+      - command: test "$ILC_VALUE" != admin
+        message: is reserved
+run: echo "$ILC_INPUT_NAME $ILC_INPUT_START $ILC_INPUT_END"
+`
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "ilc.yml")
+	assert.NoError(t, os.WriteFile(configPath, []byte(content), 0o644))
+
+	run := func(args ...string) (string, error) {
+		var outBuf bytes.Buffer
+		r := Runner{
+			Name:         "ILC",
+			Stdout:       &outBuf,
+			Stderr:       &outBuf,
+			HistoryStore: &MockHistoryStore{History: &History{Records: make(map[string][][]string)}},
+		}
+		if err := r.Parse(append([]string{"ilc", "-non-interactive", configPath}, args...)); err != nil {
+			return "", err
+		}
+		err := r.Run()
+		return outBuf.String(), err
+	}
+
+	out, err := run("-name", "web", "-start", "2024-01-01", "-end", "2024-01-05")
+	assert.NoError(t, err)
+	assert.Equal(t, "web 2024-01-01 2024-01-05\n", out)
+
+	_, err = run("-name", "web", "-end", "2024-01-01", "-start", "2024-01-05")
+	assert.EqualError(t, err, `invalid value "2024-01-01" for input end: must be after start`)
+
+	_, err = run("-name", "admin", "-start", "2024-01-01", "-end", "2024-01-05")
+	assert.EqualError(t, err, `invalid value "admin" for input name: is reserved`)
+}
+
+func TestRunner_RunTransform(t *testing.T) {
+	content := `
+inputs:
+  env:
+    options: [dev, prod]
+  service:
+    default: web
+    remember: true
+    transform:
+      - trim
+      - slugify
+      - template: '{{ .Input.env }}-{{ .Value }}'
+  tags:
+    type: list
+    required: false
+    transform: [upper]
+run: echo "$ILC_INPUT_SERVICE {{ .Input.service }} $ILC_INPUT_TAGS"
+`
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "ilc.yml")
+	assert.NoError(t, os.WriteFile(configPath, []byte(content), 0o644))
+
+	var outBuf bytes.Buffer
+	mockStore := &MockHistoryStore{History: &History{Records: make(map[string][][]string)}}
+	r := Runner{
+		Name:         "ILC",
+		Stdout:       &outBuf,
+		Stderr:       &outBuf,
+		HistoryStore: mockStore,
+	}
+	assert.NoError(t, r.Parse([]string{"ilc", "-non-interactive", configPath, "-service", " My API ", "-env", "prod", "-tags", "a,b"}))
+	assert.NoError(t, r.Run())
+	assert.Equal(t, "prod-my-api prod-my-api A,B\n", outBuf.String())
+	record := mockStore.Saved[0].Records[configPath][0]
+	assert.Contains(t, record, "--service=prod-my-api", "history records transformed values")
+
+	outBuf.Reset()
+	r = Runner{
+		Name:         "ILC",
+		Stdout:       &outBuf,
+		Stderr:       &outBuf,
+		HistoryStore: mockStore,
+	}
+	assert.NoError(t, r.Parse([]string{"ilc", "-non-interactive", configPath, "!"}))
+	assert.NoError(t, r.Run())
+	assert.Equal(t, "prod-my-api prod-my-api A,B\n", outBuf.String(), "replayed values aren't transformed again")
+
+	outBuf.Reset()
+	r = Runner{
+		Name:         "ILC",
+		Stdout:       &outBuf,
+		Stderr:       &outBuf,
+		HistoryStore: mockStore,
+	}
+	assert.NoError(t, r.Parse([]string{"ilc", "-non-interactive", configPath, "-env", "prod"}))
+	assert.NoError(t, r.Run())
+	assert.Equal(t, "prod-my-api prod-my-api \n", outBuf.String(), "remembered values aren't transformed again")
+}
+
+func TestRunner_RunRemember(t *testing.T) {
+	content := `
+remember: true
+inputs:
+  cluster:
+    default: local
+  token:
+    secret: true
+    default: none
+commands:
+  deploy:
+    inputs:
+      app:
+        default: web
+        remember: false
+      region:
+        options: [us, eu]
+        default: us
+    run: echo "$ILC_INPUT_CLUSTER $ILC_INPUT_APP $ILC_INPUT_REGION $ILC_INPUT_TOKEN"
+  status:
+    run: echo "$ILC_INPUT_CLUSTER"
+`
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "ilc.yml")
+	assert.NoError(t, os.WriteFile(configPath, []byte(content), 0o644))
+
+	run := func(store *MockHistoryStore, args ...string) (string, error) {
+		var outBuf bytes.Buffer
+		r := Runner{
+			Name:         "ILC",
+			Stdout:       &outBuf,
+			Stderr:       &outBuf,
+			HistoryStore: store,
+		}
+		if err := r.Parse(append([]string{"ilc", "-non-interactive", configPath}, args...)); err != nil {
+			return "", err
+		}
+		err := r.Run()
+		return outBuf.String(), err
+	}
+	newStore := func() *MockHistoryStore {
+		return &MockHistoryStore{History: &History{Records: make(map[string][][]string)}}
+	}
+
+	t.Run("latest entry", func(t *testing.T) {
+		store := newStore()
+		_, err := run(store, "deploy", "-cluster", "prod", "-app", "api", "-region", "eu")
+		assert.NoError(t, err)
+		_, err = run(store, "deploy", "-cluster", "staging", "-app", "api", "-region", "us")
+		assert.NoError(t, err)
+		assert.Equal(t, "deploy", store.History.Records[configPath][0][0], "history records start with the subcommand")
+		out, err := run(store, "deploy")
+		assert.NoError(t, err)
+		assert.Equal(t, "staging web us none\n", out)
+	})
+
+	t.Run("shared inputs", func(t *testing.T) {
+		store := newStore()
+		_, err := run(store, "deploy", "-cluster", "prod", "-app", "api", "-region", "eu")
+		assert.NoError(t, err)
+		_, err = run(store, "status", "-cluster", "staging")
+		assert.NoError(t, err)
+		out, err := run(store, "deploy")
+		assert.NoError(t, err)
+		assert.Equal(t, "staging web eu none\n", out)
+	})
+
+	t.Run("given values", func(t *testing.T) {
+		store := newStore()
+		_, err := run(store, "status", "-cluster", "staging")
+		assert.NoError(t, err)
+		out, err := run(store, "status", "-cluster", "dev")
+		assert.NoError(t, err)
+		assert.Equal(t, "dev\n", out)
+	})
+
+	t.Run("invalid values", func(t *testing.T) {
+		store := newStore()
+		assert.NoError(t, os.WriteFile(configPath, []byte(strings.Replace(content, "[us, eu]", "[us, eu, ap]", 1)), 0o644))
+		_, err := run(store, "deploy", "-cluster", "prod", "-region", "ap")
+		assert.NoError(t, err)
+		assert.NoError(t, os.WriteFile(configPath, []byte(content), 0o644))
+		out, err := run(store, "deploy")
+		assert.NoError(t, err)
+		assert.Equal(t, "prod web us none\n", out)
+	})
+}
+
+func TestRunner_UseHistorySuggestions(t *testing.T) {
+	content := `
+inputs:
+  cluster:
+    suggestions: [local]
+  token:
+    secret: true
+  env:
+    options: [dev, prod]
+commands:
+  deploy:
+    inputs:
+      app: string
+    run: echo
+`
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "ilc.yml")
+	assert.NoError(t, os.WriteFile(configPath, []byte(content), 0o644))
+	store := &MockHistoryStore{History: &History{Records: make(map[string][][]string)}}
+	for _, args := range [][]string{
+		{"deploy", "-cluster", "prod", "-env", "dev", "-app", "api", "-token", "a"},
+		{"deploy", "-cluster", "local", "-env", "prod", "-app", "web", "-token", "b"},
+		{"deploy", "-cluster", "staging", "-env", "prod", "-app", "api", "-token", "c"},
+	} {
+		r := &Runner{Name: "ILC", Stdout: io.Discard, HistoryStore: store}
+		assert.NoError(t, r.Parse(append([]string{"ilc", "-non-interactive", configPath}, args...)))
+		assert.NoError(t, r.Run())
+	}
+	assert.Len(t, store.History.Records[configPath], 3)
+
+	newRunner := func(args ...string) *Runner {
+		r := &Runner{
+			Name:         "ILC",
+			HistoryStore: store,
+		}
+		assert.NoError(t, r.Parse(append([]string{"ilc"}, append(args, configPath)...)))
+		return r
+	}
+
+	r := newRunner()
+	r.useHistory()
+	selection, err := r.Config.Select([]string{"deploy"})
+	assert.NoError(t, err)
+	suggestions := map[string][]string{}
+	for _, input := range selection.Inputs().Inputs() {
+		suggestions[input.Name] = input.Suggestions
+	}
+	assert.Equal(t, map[string][]string{
+		"cluster": {"staging", "local", "prod"},
+		"token":   nil,
+		"env":     nil,
+		"app":     {"api", "web"},
+	}, suggestions)
+
+	r = newRunner("-non-interactive")
+	r.useHistory()
+	selection, _ = r.Config.Select([]string{"deploy"})
+	assert.Equal(t, []string{"local"}, selection.Inputs().Inputs()[0].Suggestions, "history isn't suggested when not prompting")
+}
+
+func TestRunner_RunInputValues(t *testing.T) {
+	content := `
+inputs:
+  name: string
+  replicas:
+    type: integer
+commands:
+  deploy:
+    run: echo "$ILC_INPUT_NAME $ILC_INPUT_REPLICAS"
+`
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "ilc.yml")
+	assert.NoError(t, os.WriteFile(configPath, []byte(content), 0o644))
+	valuesPath := filepath.Join(dir, "values.yml")
+	assert.NoError(t, os.WriteFile(valuesPath, []byte("name: file\nreplicas: 2\n"), 0o644))
+
+	run := func(stdin string, env map[string]string, args ...string) (string, error) {
+		var outBuf bytes.Buffer
+		r := Runner{
+			Name:         "ILC",
+			Env:          env,
+			Stdin:        strings.NewReader(stdin),
+			Stdout:       &outBuf,
+			Stderr:       &outBuf,
+			HistoryStore: &MockHistoryStore{History: &History{Records: make(map[string][][]string)}},
+		}
+		if err := r.Parse(append([]string{"ilc", "-non-interactive"}, args...)); err != nil {
+			return "", err
+		}
+		err := r.Run()
+		return outBuf.String(), err
+	}
+
+	t.Run("file", func(t *testing.T) {
+		out, err := run("", nil, "-inputs-file", valuesPath, configPath, "deploy")
+		assert.NoError(t, err)
+		assert.Equal(t, "file 2\n", out)
+	})
+
+	t.Run("precedence", func(t *testing.T) {
+		out, err := run(`{"replicas": 4}`, map[string]string{"ILC_INPUT_NAME": "env"}, "-inputs-file", valuesPath, "-inputs-json", "-", configPath, "deploy", "-replicas", "5")
+		assert.NoError(t, err)
+		assert.Equal(t, "env 5\n", out)
+		out, err = run("", nil, "-inputs-file", valuesPath, "-inputs-json", `{"replicas": 4}`, configPath, "deploy")
+		assert.NoError(t, err)
+		assert.Equal(t, "file 4\n", out)
+	})
+
+	t.Run("unknown inputs", func(t *testing.T) {
+		_, err := run(`{"nmae": "x"}`, nil, "-inputs-json", "-", configPath, "deploy")
+		assert.EqualError(t, err, "invalid input values: unknown inputs: nmae")
+	})
+
+	t.Run("invalid values", func(t *testing.T) {
+		_, err := run(`{"name": "x", "replicas": "many"}`, nil, "-inputs-json", "-", configPath, "deploy")
+		assert.ErrorContains(t, err, `invalid value "many" for input replicas`)
+	})
+
+	t.Run("dump", func(t *testing.T) {
+		dumpPath := filepath.Join(dir, "dump.json")
+		out, err := run("", nil, "-dump-inputs", dumpPath, configPath, "deploy", "-name", "web", "-replicas", "3")
+		assert.NoError(t, err)
+		assert.Equal(t, "web 3\n", out)
+		dump, err := os.ReadFile(dumpPath)
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{"name": "web", "replicas": 3}`, string(dump))
+
+		out, err = run("", nil, "-inputs-file", dumpPath, configPath, "deploy")
+		assert.NoError(t, err)
+		assert.Equal(t, "web 3\n", out)
+	})
+}
@@ -2,8 +2,10 @@ package ilc
 
 import (
 	"fmt"
+	"maps"
 	"os"
 	"os/exec"
+	"slices"
 	"strings"
 	"text/template"
 
@@ -15,17 +17,32 @@ type Selection struct {
 	Args     []string
 }
 
-func (selection Selection) Select(args []string) Selection {
-	if len(args) > 0 {
-		commands := selection.commands
-		if subcommand, found := commands[len(commands)-1].Get(args[0]); found {
-			selection = selection.SelectCommand(subcommand.Command, args[1:])
-			return selection.Select(selection.Args)
+// Select walks the arguments selecting subcommands. Inputs of the commands
+// selected so far may be given before or between subcommand names and are
+// collected, along with the arguments following the last subcommand, into Args.
+func (selection Selection) Select(args []string) (Selection, error) {
+	var inputArgs []string
+	for len(args) > 0 {
+		if subcommand, found := selection.commands[len(selection.commands)-1].Get(args[0]); found {
+			selection = selection.SelectCommand(subcommand.Command, nil)
+			args = args[1:]
+			continue
+		}
+		n := selection.Inputs().OptionArgs(args)
+		if n == 0 {
+			break
+		}
+		if n > 1 {
+			if _, found := selection.commands[len(selection.commands)-1].Get(args[1]); found {
+				return selection, fmt.Errorf("%w: %q is both a value for %s and a command, use %s=%s to give it as a value", ErrAmbiguousArgument, args[1], args[0], args[0], args[1])
+			}
 		}
+		inputArgs = append(inputArgs, args[:n]...)
+		args = args[n:]
 	}
 	newSelection := NewSelection(selection.commands[0], selection.commands[1:]...)
-	newSelection.Args = args
-	return newSelection
+	newSelection.Args = append(append(newSelection.Args, inputArgs...), args...)
+	return newSelection, nil
 }
 
 func (selection Selection) SelectCommand(command Command, args []string) Selection {
@@ -79,16 +96,105 @@ func (selection Selection) Pure() bool {
 	return selection.commands[len(selection.commands)-1].Pure
 }
 
+// Inputs returns the inputs of the selected commands, which are read from and
+// exported as environment variables with the env_prefix of their command, or
+// the nearest parent with one.
 func (selection Selection) Inputs() Inputs {
 	inps := Inputs{FlagSet: inputs.NewFlagSet("ilc", EnvVarPrefix)}
+	prefix := EnvVarPrefix
 	for _, command := range selection.commands {
+		if command.EnvPrefix != nil {
+			prefix = *command.EnvPrefix
+		}
 		if command.Inputs.FlagSet != nil {
-			inps.FlagSet = inps.FlagSet.Merge(command.Inputs.FlagSet)
+			inps.FlagSet = inps.FlagSet.Merge(command.Inputs.WithEnvPrefix(prefix))
 		}
 	}
 	return inps
 }
 
+// Remembered returns the inputs of the selected commands whose last used
+// values become their defaults. Inputs are remembered when they set remember,
+// or otherwise their command or the nearest parent with it set does. Secret
+// inputs are never remembered.
+func (selection Selection) Remembered() []*inputs.Input {
+	var remembered []*inputs.Input
+	remember := false
+	for _, command := range selection.commands {
+		if command.Remember != nil {
+			remember = *command.Remember
+		}
+		if command.Inputs.FlagSet == nil {
+			continue
+		}
+		for _, input := range command.Inputs.Inputs() {
+			if input.Secret {
+				continue
+			}
+			if input.Remember != nil && *input.Remember || input.Remember == nil && remember {
+				remembered = append(remembered, input)
+			}
+		}
+	}
+	return remembered
+}
+
+// Presets returns the presets of the selected commands, with those of
+// subcommands replacing the presets of the same name of their parents.
+func (selection Selection) Presets() Presets {
+	var presets Presets
+	for _, command := range selection.commands {
+		for _, preset := range command.Presets {
+			if i := slices.IndexFunc(presets, func(p Preset) bool { return p.Name == preset.Name }); i >= 0 {
+				presets[i] = preset
+			} else {
+				presets = append(presets, preset)
+			}
+		}
+	}
+	return presets
+}
+
+// PresetValues returns the values of the named preset overridden by the
+// values given. No preset is used when the name is empty. Presets cascade to
+// subcommands, so the values of the preset for inputs the selected commands
+// don't declare are left out.
+func (selection Selection) PresetValues(name string, values map[string]string) (map[string]string, error) {
+	merged := map[string]string{}
+	if name != "" {
+		preset, found := selection.Presets().Get(name)
+		if !found {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownPreset, name)
+		}
+		inps := selection.Inputs()
+		for input, value := range preset.Values {
+			if inps.Has(input) {
+				merged[input] = value
+			}
+		}
+	}
+	maps.Copy(merged, values)
+	return merged, nil
+}
+
+func (selection Selection) CommandArgs() *CommandArgs {
+	return selection.commands[len(selection.commands)-1].Args
+}
+
+func (selection Selection) InputArgs() []string {
+	if i := slices.Index(selection.Args, ArgsSeparator); i >= 0 {
+		return selection.Args[:i]
+	}
+	return selection.Args
+}
+
+func (selection Selection) ScriptArgs() []string {
+	if i := slices.Index(selection.Args, ArgsSeparator); i >= 0 {
+		return selection.Args[i+1:]
+	}
+	return []string{}
+}
+
 func (selection Selection) Commands() SubCommands {
 	return selection.commands[len(selection.commands)-1].Commands
 }
@@ -169,20 +275,36 @@ func (selection Selection) Cmd(data TemplateData, moreEnv EnvMap) (*exec.Cmd, er
 		env = moreEnv.Merge(env)
 	}
 	shell = append(shell, scriptFile)
+	shell = append(shell, selection.ScriptArgs()...)
 	cmd := exec.Command(shell[0], shell[1:]...)
 	cmd.Env = env.ToList()
 	return cmd, nil
 }
 
+// RedactedArgs returns the names of the selected subcommands followed by the
+// arguments, with the values of secret inputs redacted.
+func (selection Selection) RedactedArgs() []string {
+	var args []string
+	for _, command := range selection.commands[1:] {
+		args = append(args, command.Name)
+	}
+	return append(args, selection.Inputs().RedactArgs(selection.Args)...)
+}
+
+// ToArgs returns the names of the selected subcommands followed by the
+// arguments of the inputs, which selecting from the config selects the same.
 func (selection Selection) ToArgs() []string {
 	inputArgs := selection.Inputs().ToArgs()
 	args := make([]string, 0, len(selection.commands)+len(inputArgs))
-	for _, command := range selection.commands {
-		if command.Name != "" {
-			args = append(args, command.Name)
-		}
+	for _, command := range selection.commands[1:] {
+		args = append(args, command.Name)
+	}
+	args = append(args, inputArgs...)
+	if scriptArgs := selection.ScriptArgs(); len(scriptArgs) > 0 {
+		args = append(args, ArgsSeparator)
+		args = append(args, scriptArgs...)
 	}
-	return append(args, inputArgs...)
+	return args
 }
 
 func NewSelection(command Command, commands ...Command) Selection {
@@ -24,7 +24,8 @@ func TestSelectionSelect(t *testing.T) {
 			commands: selection.commands,
 			Args:     []string{},
 		}
-		actual := selection.Select([]string{})
+		actual, err := selection.Select([]string{})
+		assert.NoError(t, err)
 		assert.Equal(t, expected, actual)
 		assert.NotEqual(t, selection, actual)
 	})
@@ -33,7 +34,8 @@ func TestSelectionSelect(t *testing.T) {
 			commands: selection.commands,
 			Args:     []string{"baz"},
 		}
-		actual := selection.Select([]string{"baz"})
+		actual, err := selection.Select([]string{"baz"})
+		assert.NoError(t, err)
 		assert.Equal(t, expected, actual)
 		assert.NotEqual(t, selection, actual)
 	})
@@ -42,7 +44,8 @@ func TestSelectionSelect(t *testing.T) {
 			commands: append(selection.commands, selection.commands[0].Commands[0].Command),
 			Args:     []string{"baz"},
 		}
-		actual := selection.Select([]string{"bar", "baz"})
+		actual, err := selection.Select([]string{"bar", "baz"})
+		assert.NoError(t, err)
 		assert.Equal(t, expected, actual)
 		assert.NotEqual(t, selection, actual)
 	})
@@ -259,6 +262,88 @@ func TestSelectionInputs(t *testing.T) {
 	})
 }
 
+func TestSelectionPresets(t *testing.T) {
+	parentInputs := inputs.NewFlagSet("ilc", EnvVarPrefix)
+	parentInputs.Var(&inputs.Input{Name: "env", Value: &inputs.StringValue{}})
+	childInputs := inputs.NewFlagSet("ilc", EnvVarPrefix)
+	childInputs.Var(&inputs.Input{Name: "replicas", Value: &inputs.IntegerValue{}})
+	selection := NewSelection(
+		Command{Inputs: Inputs{FlagSet: parentInputs}, Presets: Presets{
+			{Name: "staging", Values: map[string]string{"env": "staging"}},
+			{Name: "prod", Values: map[string]string{"env": "prod"}},
+		}},
+		Command{Inputs: Inputs{FlagSet: childInputs}, Presets: Presets{
+			{Name: "prod", Values: map[string]string{"env": "prod", "replicas": "5"}},
+			{Name: "canary", Values: map[string]string{"canary": "true"}},
+		}},
+	)
+	expected := Presets{
+		{Name: "staging", Values: map[string]string{"env": "staging"}},
+		{Name: "prod", Values: map[string]string{"env": "prod", "replicas": "5"}},
+		{Name: "canary", Values: map[string]string{"canary": "true"}},
+	}
+	assert.Equal(t, expected, selection.Presets())
+
+	t.Run("values", func(t *testing.T) {
+		values, err := selection.PresetValues("prod", map[string]string{"replicas": "2"})
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]string{"env": "prod", "replicas": "2"}, values)
+		values, err = selection.PresetValues("", map[string]string{"replicas": "2"})
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]string{"replicas": "2"}, values)
+		values, err = selection.PresetValues("canary", nil)
+		assert.NoError(t, err)
+		assert.Empty(t, values, "values for inputs not declared are left out")
+		_, err = selection.PresetValues("qa", nil)
+		assert.ErrorIs(t, err, ErrUnknownPreset)
+	})
+}
+
+func TestSelectionInputsEnvPrefix(t *testing.T) {
+	newInputs := func(names ...string) Inputs {
+		fs := inputs.NewFlagSet("ilc", EnvVarPrefix)
+		for _, name := range names {
+			fs.Var(&inputs.Input{Name: name, Value: &inputs.StringValue{}})
+		}
+		return Inputs{FlagSet: fs}
+	}
+	empty, app := "", "APP_"
+	selection := NewSelection(
+		Command{Inputs: newInputs("env")},
+		Command{EnvPrefix: &app, Inputs: newInputs("region")},
+		Command{Inputs: newInputs("tag")},
+		Command{EnvPrefix: &empty, Inputs: newInputs("image")},
+	)
+	inps := selection.Inputs()
+	var envs []string
+	for _, input := range inps.Inputs() {
+		envs = append(envs, inps.EnvVar(input))
+	}
+	assert.Equal(t, []string{"ILC_INPUT_ENV", "APP_REGION", "APP_TAG", "IMAGE"}, envs)
+}
+
+func TestSelectionRemembered(t *testing.T) {
+	yes, no := true, false
+	fs := inputs.NewFlagSet("ilc", EnvVarPrefix)
+	fs.Var(&inputs.Input{Name: "cluster", Value: &inputs.StringValue{}})
+	fs.Var(&inputs.Input{Name: "token", Secret: true, Value: &inputs.StringValue{}})
+	fs.Var(&inputs.Input{Name: "tag", Remember: &yes, Value: &inputs.StringValue{}})
+	subFs := inputs.NewFlagSet("ilc", EnvVarPrefix)
+	subFs.Var(&inputs.Input{Name: "app", Value: &inputs.StringValue{}})
+	subFs.Var(&inputs.Input{Name: "region", Remember: &no, Value: &inputs.StringValue{}})
+
+	names := func(selection Selection) []string {
+		var names []string
+		for _, input := range selection.Remembered() {
+			names = append(names, input.Name)
+		}
+		return names
+	}
+	assert.Equal(t, []string{"tag"}, names(NewSelection(Command{Inputs: Inputs{FlagSet: fs}}, Command{Inputs: Inputs{FlagSet: subFs}})))
+	assert.Equal(t, []string{"cluster", "tag", "app"}, names(NewSelection(Command{Remember: &yes, Inputs: Inputs{FlagSet: fs}}, Command{Inputs: Inputs{FlagSet: subFs}})))
+	assert.Equal(t, []string{"cluster", "tag"}, names(NewSelection(Command{Remember: &yes, Inputs: Inputs{FlagSet: fs}}, Command{Remember: &no, Inputs: Inputs{FlagSet: subFs}})))
+}
+
 func TestSelectionRenderScript(t *testing.T) {
 	t.Run("template error", func(t *testing.T) {
 		data := TemplateData{
@@ -514,7 +599,7 @@ func TestSelectionCmd(t *testing.T) {
 func TestSelectionToArgs(t *testing.T) {
 	selection := NewSelection(
 		Command{
-			Name: "",
+			Name: "ilc.yml",
 			Inputs: func() Inputs {
 				fs := inputs.NewFlagSet("ilc", EnvVarPrefix)
 				fs.Var(&inputs.Input{Name: "arg1", Value: &inputs.StringValue{Value: "foobar"}})
@@ -540,11 +625,42 @@ func TestSelectionToArgs(t *testing.T) {
 			}(),
 		},
 	)
-	expected := []string{"command1", "command2", "-arg1", "foobar", "-arg2", "123", "-arg3"}
+	expected := []string{"command1", "command2", "--arg1=foobar", "--arg2=123", "--arg3"}
 	actual := selection.ToArgs()
 	assert.Equal(t, expected, actual)
 }
 
+func TestSelectionInputAndScriptArgs(t *testing.T) {
+	t.Run("without separator", func(t *testing.T) {
+		selection := NewSelection(Command{})
+		selection.Args = []string{"-foo", "bar"}
+		assert.Equal(t, []string{"-foo", "bar"}, selection.InputArgs())
+		assert.Equal(t, []string{}, selection.ScriptArgs())
+	})
+	t.Run("with separator", func(t *testing.T) {
+		selection := NewSelection(Command{})
+		selection.Args = []string{"-foo", "bar", "--", "baz", "-qux"}
+		assert.Equal(t, []string{"-foo", "bar"}, selection.InputArgs())
+		assert.Equal(t, []string{"baz", "-qux"}, selection.ScriptArgs())
+	})
+}
+
+func TestSelectionCmdScriptArgs(t *testing.T) {
+	selection := NewSelection(Command{Shell: []string{"/bin/sh"}, Run: "foobar"})
+	selection.Args = []string{"--", "a", "b"}
+	cmd, err := selection.Cmd(TemplateData{}, EnvMap{})
+	assert.NoError(t, err)
+	defer os.Remove(cmd.Args[1])
+	assert.Equal(t, []string{"a", "b"}, cmd.Args[2:])
+}
+
+func TestSelectionToArgsWithScriptArgs(t *testing.T) {
+	selection := NewSelection(Command{}, Command{Name: "command1"})
+	selection.Args = []string{"--", "a", "b"}
+	expected := []string{"command1", "--", "a", "b"}
+	assert.Equal(t, expected, selection.ToArgs())
+}
+
 func readTextFile(name string) (string, error) {
 	var str strings.Builder
 	data, err := os.ReadFile(name)
@@ -556,3 +672,57 @@ func readTextFile(name string) (string, error) {
 	}
 	return str.String(), nil
 }
+
+func TestSelectionSelectWithInputs(t *testing.T) {
+	newInputs := func(inps ...*inputs.Input) Inputs {
+		fs := inputs.NewFlagSet("ilc", EnvVarPrefix)
+		for _, input := range inps {
+			fs.Var(input)
+		}
+		return Inputs{FlagSet: fs}
+	}
+	deploy := Command{
+		Name:   "deploy",
+		Inputs: newInputs(&inputs.Input{Name: "service", Value: &inputs.StringValue{}}),
+		Commands: SubCommands{
+			{Command: Command{Name: "api", Run: "echo api"}},
+		},
+	}
+	root := Command{
+		Inputs: newInputs(
+			&inputs.Input{Name: "region", Short: "r", Value: &inputs.StringValue{}},
+			&inputs.Input{Name: "force", Short: "f", Value: &inputs.BooleanValue{}},
+		),
+		Commands: SubCommands{{Command: deploy}},
+	}
+
+	t.Run("inputs before subcommands", func(t *testing.T) {
+		actual, err := NewSelection(root).Select([]string{"-region", "us", "deploy", "api", "x"})
+		assert.NoError(t, err)
+		assert.Equal(t, "deploy api", actual.String())
+		assert.Equal(t, []string{"-region", "us", "x"}, actual.Args)
+	})
+	t.Run("inputs between subcommands", func(t *testing.T) {
+		actual, err := NewSelection(root).Select([]string{"deploy", "--force", "-service", "web", "-r=eu", "api"})
+		assert.NoError(t, err)
+		assert.Equal(t, "deploy api", actual.String())
+		assert.Equal(t, []string{"--force", "-service", "web", "-r=eu"}, actual.Args)
+	})
+	t.Run("descendant inputs are not accepted early", func(t *testing.T) {
+		actual, err := NewSelection(root).Select([]string{"-service", "web", "deploy", "api"})
+		assert.NoError(t, err)
+		assert.Equal(t, "", actual.String())
+		assert.Equal(t, []string{"-service", "web", "deploy", "api"}, actual.Args)
+	})
+	t.Run("ambiguous value", func(t *testing.T) {
+		_, err := NewSelection(root).Select([]string{"-region", "deploy", "api"})
+		assert.ErrorIs(t, err, ErrAmbiguousArgument)
+		assert.ErrorContains(t, err, `"deploy" is both a value for -region and a command, use -region=deploy`)
+	})
+	t.Run("attached value is not ambiguous", func(t *testing.T) {
+		actual, err := NewSelection(root).Select([]string{"-region=deploy", "deploy", "api"})
+		assert.NoError(t, err)
+		assert.Equal(t, "deploy api", actual.String())
+		assert.Equal(t, []string{"-region=deploy"}, actual.Args)
+	})
+}
@@ -6,6 +6,7 @@ import (
 	"io"
 	"strings"
 
+	"github.com/evilmarty/ilc/internal/inputs"
 	"github.com/muesli/termenv"
 )
 
@@ -14,7 +15,10 @@ type Usage struct {
 	Description string
 	commands    [][]string
 	inputs      [][]string
+	presets     [][]string
+	positional  []string
 	flags       [][]string
+	args        *CommandArgs
 	Entrypoint  []string
 	output      *termenv.Output
 }
@@ -64,9 +68,19 @@ func (u Usage) usage() string {
 	if len(u.commands) > 0 {
 		params = append(params, "<commands>")
 	}
+	for _, name := range u.positional {
+		params = append(params, "<"+name+">")
+	}
 	if len(u.inputs) > 0 {
 		params = append(params, "[inputs]")
 	}
+	if u.args != nil {
+		if u.args.Min > 0 {
+			params = append(params, ArgsSeparator+" <args>...")
+		} else {
+			params = append(params, "["+ArgsSeparator+" <args>...]")
+		}
+	}
 	return strings.Join(params, " ")
 }
 
@@ -93,7 +107,13 @@ func (u Usage) String() string {
 		u.printInstructions(&b, u.commands, "COMMANDS", "")
 	}
 	if len(u.inputs) > 0 {
-		u.printInstructions(&b, u.inputs, "INPUTS", "-")
+		u.printInstructions(&b, u.inputs, "INPUTS", "")
+	}
+	if len(u.presets) > 0 {
+		u.printInstructions(&b, u.presets, "PRESETS", "")
+	}
+	if u.args != nil && u.args.Description != "" {
+		u.printSection(&b, "ARGUMENTS", u.args.Description+"\n")
 	}
 	if len(u.flags) > 0 {
 		u.printInstructions(&b, u.flags, "FLAGS", "-")
@@ -111,8 +131,12 @@ func (u *Usage) AddCommand(description, name string, aliases ...string) {
 	u.commands = append(u.commands, append([]string{description, name}, aliases...))
 }
 
-func (u *Usage) AddInput(description, name string) {
-	u.inputs = append(u.inputs, []string{description, name})
+func (u *Usage) AddInput(description, name string, aliases ...string) {
+	u.inputs = append(u.inputs, append([]string{description, name}, aliases...))
+}
+
+func (u *Usage) AddPreset(description, name string) {
+	u.presets = append(u.presets, []string{description, name})
 }
 
 func (u *Usage) AddFlag(description, name string) {
@@ -128,17 +152,69 @@ func (u *Usage) ImportCommands(commands SubCommands) *Usage {
 
 func (u *Usage) ImportInputs(inputs Inputs) *Usage {
 	for _, input := range inputs.Inputs() {
-		u.AddInput(input.Description, input.Name)
+		names := input.OptionNames()
+		description := input.Description
+		if !input.Required() {
+			defaultValue := input.DisplayValue()
+			if defaultTemplate, ok := input.DefaultFrom.(TemplateDefault); ok {
+				defaultValue = string(defaultTemplate)
+			}
+			description = strings.TrimSpace(fmt.Sprintf("%s (default: %s)", description, defaultValue))
+		}
+		// Only environment variables not named the usual way are worth noting
+		if env := inputs.EnvVar(input); env != EnvVarPrefix+input.EnvName() {
+			description = strings.TrimSpace(fmt.Sprintf("%s (env: %s)", description, env))
+		}
+		u.AddInput(description, names[0], names[1:]...)
+		u.addOptions(input.Options)
+	}
+	for _, input := range inputs.PositionalInputs() {
+		u.positional = append(u.positional, input.Name)
+	}
+	return u
+}
+
+// addOptions lists the options of an input beneath it, headed by their groups.
+func (u *Usage) addOptions(options inputs.InputOptions) {
+	for i, option := range options {
+		indent := "  "
+		if group, ok := inputs.GroupHeading(options, i); ok {
+			u.AddInput("", indent+group+":")
+		}
+		if option.Group != "" {
+			indent += "  "
+		}
+		description := option.Description
+		if option.Label != option.Value {
+			description = strings.TrimSuffix(fmt.Sprintf("%s - %s", option.Label, description), " - ")
+		}
+		if option.Disabled != "" {
+			description = strings.TrimSpace(fmt.Sprintf("%s (disabled: %s)", description, option.Disabled))
+		}
+		u.AddInput(description, indent+option.Value)
+	}
+}
+
+func (u *Usage) ImportPresets(presets Presets) *Usage {
+	for _, preset := range presets {
+		u.AddPreset(preset.Summary(), preset.Name)
 	}
 	return u
 }
 
+func (u *Usage) ImportArgs(args *CommandArgs) *Usage {
+	u.args = args
+	return u
+}
+
 func (u *Usage) ImportSelection(commands Selection) *Usage {
 	u.Description = commands.Description()
 	if s := commands.String(); s != "" {
 		u.Entrypoint = append(u.Entrypoint, s)
 	}
 	u.ImportInputs(commands.Inputs())
+	u.ImportPresets(commands.Presets())
+	u.ImportArgs(commands.CommandArgs())
 	u.ImportCommands(commands.Commands())
 	return u
 }
@@ -22,8 +22,8 @@ USAGE
   ilc config.yaml subcommand [inputs]
 
 INPUTS
-  -c                   c input
-  -d                   d input
+  --c                  c input
+  --d                  d input
 
 
 `
@@ -68,8 +68,8 @@ COMMANDS
   b                    b subcommand
 
 INPUTS
-  -c                   c input
-  -d                   d input
+  --c                  c input
+  --d                  d input
 
 
 `
@@ -93,8 +93,8 @@ COMMANDS
   b                    b subcommand
 
 INPUTS
-  -c                   c input
-  -d                   d input
+  --c                  c input
+  --d                  d input
 
 
 `
@@ -118,8 +118,8 @@ COMMANDS
   b                    b subcommand
 
 INPUTS
-  -c                   c input
-  -d                   d input
+  --c                  c input
+  --d                  d input
 
 
 `
@@ -143,8 +143,8 @@ COMMANDS
   b                    b subcommand
 
 INPUTS
-  -c                   c input
-  -d                   d input
+  --c                  c input
+  --d                  d input
 
 
 `
@@ -166,8 +166,8 @@ COMMANDS
   b                    b subcommand
 
 INPUTS
-  -c                   c input
-  -d                   d input
+  --c                  c input
+  --d                  d input
 
 
 `
@@ -189,8 +189,8 @@ COMMANDS
   b                    b subcommand
 
 INPUTS
-  -c                   c input
-  -d                   d input
+  --c                  c input
+  --d                  d input
 
 
 `
@@ -232,6 +232,108 @@ func TestUsage_ImportSelection(t *testing.T) {
 	assert.Contains(t, u.String(), "sub command")
 }
 
+func TestUsage_Presets(t *testing.T) {
+	u := NewUsage(os.Stdout)
+	u.ImportPresets(Presets{
+		{Name: "staging-canary", Values: map[string]string{"env": "staging", "canary": "true"}},
+	})
+	assert.Contains(t, u.String(), "PRESETS\n  staging-canary       canary=true, env=staging\n")
+}
+
+func TestUsage_Args(t *testing.T) {
+	t.Run("optional", func(t *testing.T) {
+		u := usageFixture()
+		u.ImportArgs(&CommandArgs{})
+		assert.Contains(t, u.String(), "ilc config.yaml subcommand <commands> [inputs] [-- <args>...]\n")
+		assert.NotContains(t, u.String(), "ARGUMENTS")
+	})
+	t.Run("required with description", func(t *testing.T) {
+		u := usageFixture()
+		u.ImportArgs(&CommandArgs{Min: 1, Description: "files to process"})
+		assert.Contains(t, u.String(), "ilc config.yaml subcommand <commands> [inputs] -- <args>...\n")
+		assert.Contains(t, u.String(), "ARGUMENTS\n  files to process\n")
+	})
+}
+
+func TestUsage_PositionalInputs(t *testing.T) {
+	fs := inputs.NewFlagSet("ilc", EnvVarPrefix)
+	fs.Var(&inputs.Input{Name: "service", Position: 2, Value: &inputs.StringValue{}})
+	fs.Var(&inputs.Input{Name: "env", Position: 1, Value: &inputs.StringValue{}})
+	fs.Var(&inputs.Input{Name: "force", Value: &inputs.BooleanValue{}})
+	u := NewUsage(os.Stdout)
+	u.Entrypoint = []string{"ilc", "tool.yml", "deploy"}
+	u.ImportInputs(Inputs{FlagSet: fs})
+	assert.Contains(t, u.String(), "ilc tool.yml deploy <env> <service> [inputs]\n")
+}
+
+func TestUsage_InputOptionNames(t *testing.T) {
+	fs := inputs.NewFlagSet("ilc", EnvVarPrefix)
+	fs.Var(&inputs.Input{Name: "env", Short: "e", Description: "env input", Value: &inputs.StringValue{}})
+	fs.Var(&inputs.Input{Name: "force", Description: "force input", Value: &inputs.BooleanValue{}})
+	u := NewUsage(os.Stdout)
+	u.ImportInputs(Inputs{FlagSet: fs})
+	assert.Contains(t, u.String(), "INPUTS\n  -e, --env            env input\n  --[no-]force         force input\n")
+}
+
+func TestUsage_InputDefaults(t *testing.T) {
+	fs := inputs.NewFlagSet("ilc", EnvVarPrefix)
+	fs.Var(&inputs.Input{Name: "name", Description: "name input", Optional: true, Value: &inputs.StringValue{Value: "World"}})
+	fs.Var(&inputs.Input{Name: "count", Optional: true, Value: &inputs.NumberValue{Value: 3}})
+	u := NewUsage(os.Stdout)
+	u.ImportInputs(Inputs{FlagSet: fs})
+	assert.Contains(t, u.String(), "--name               name input (default: World)\n")
+	assert.Contains(t, u.String(), "--count              (default: 3)\n")
+}
+
+func TestUsage_InputEnv(t *testing.T) {
+	fs := inputs.NewFlagSet("ilc", EnvVarPrefix)
+	fs.Var(&inputs.Input{Name: "region", Description: "region input", Value: &inputs.StringValue{}})
+	fs.Var(&inputs.Input{Name: "tag", Env: "IMAGE_TAG", Optional: true, Value: &inputs.StringValue{Value: "latest"}})
+	fs.Var(&inputs.Input{Name: "force", Value: &inputs.BooleanValue{}})
+	u := NewUsage(os.Stdout)
+	u.ImportInputs(Inputs{FlagSet: fs.WithEnvPrefix("AWS_")})
+	assert.Contains(t, u.String(), "--region             region input (env: AWS_REGION)\n")
+	assert.Contains(t, u.String(), "--tag                (default: latest) (env: IMAGE_TAG)\n")
+
+	u = NewUsage(os.Stdout)
+	u.ImportInputs(Inputs{FlagSet: fs})
+	assert.NotContains(t, u.String(), "(env: AWS_REGION)")
+}
+
+func TestUsage_InputSecretDefaults(t *testing.T) {
+	fs := inputs.NewFlagSet("ilc", EnvVarPrefix)
+	fs.Var(&inputs.Input{Name: "token", Optional: true, Secret: true, Value: &inputs.StringValue{Value: "s3cret"}})
+	u := NewUsage(os.Stdout)
+	u.ImportInputs(Inputs{FlagSet: fs})
+	assert.Contains(t, u.String(), "(default: ******)\n")
+	assert.NotContains(t, u.String(), "s3cret")
+}
+
+func TestUsage_InputTemplateDefaults(t *testing.T) {
+	fs := inputs.NewFlagSet("ilc", EnvVarPrefix)
+	fs.Var(&inputs.Input{Name: "replicas", Optional: true, DefaultFrom: TemplateDefault("{{ .Input.count }}"), Value: &inputs.NumberValue{}})
+	u := NewUsage(os.Stdout)
+	u.ImportInputs(Inputs{FlagSet: fs})
+	assert.Contains(t, u.String(), "(default: {{ .Input.count }})\n")
+}
+
+func TestUsage_InputOptions(t *testing.T) {
+	fs := inputs.NewFlagSet("ilc", EnvVarPrefix)
+	fs.Var(&inputs.Input{Name: "env", Value: &inputs.StringValue{}, Options: inputs.InputOptions{
+		{Label: "Development", Value: "dev", Description: "Local cluster", Group: "Pre-release"},
+		{Label: "staging", Value: "staging", Group: "Pre-release"},
+		{Label: "Production", Value: "prod", Group: "Release", Disabled: "Frozen"},
+	}})
+	u := NewUsage(os.Stdout)
+	u.ImportInputs(Inputs{FlagSet: fs})
+	assert.Contains(t, u.String(), "  --env                \n"+
+		"    Pre-release:       \n"+
+		"      dev              Development - Local cluster\n"+
+		"      staging          \n"+
+		"    Release:           \n"+
+		"      prod             Production (disabled: Frozen)\n")
+}
+
 func TestUsage_Print(t *testing.T) {
 	var buf bytes.Buffer
 	u := NewUsage(&buf)
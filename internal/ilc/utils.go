@@ -1,12 +1,27 @@
 package ilc
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"math"
+	"os"
+	"os/exec"
+	"regexp"
+	"strconv"
 	"strings"
 	"text/template"
+	"time"
+
+	"github.com/evilmarty/ilc/internal/inputs"
 )
 
+// DefaultOptionsTimeout is how long an options command may run for when no
+// timeout is given.
+const DefaultOptionsTimeout = 10 * time.Second
+
 var defaultTemplateFuncs = template.FuncMap{
 	"contains":   strings.Contains,
 	"startswith": strings.HasPrefix,
@@ -17,6 +32,8 @@ var renderTemplate = template.New("").Funcs(defaultTemplateFuncs)
 type TemplateData struct {
 	Input map[string]any
 	Env   map[string]string
+	Args  []string
+	Value any
 }
 
 func (td TemplateData) getInput(name string) any {
@@ -118,8 +135,12 @@ func RenderTemplate(text interface{}, data TemplateData) (string, error) {
 	case *template.Template:
 		tmpl = t
 	case string:
-		tmpl, err = renderTemplate.Funcs(data.Funcs()).Parse(t)
-		if err != nil {
+		// Clone so concurrent renders, ie. from background options and
+		// suggestions commands, don't share the parsed tree and funcs.
+		if tmpl, err = renderTemplate.Clone(); err != nil {
+			return "", err
+		}
+		if tmpl, err = tmpl.Funcs(data.Funcs()).Parse(t); err != nil {
 			return "", err
 		}
 	default:
@@ -132,6 +153,283 @@ func RenderTemplate(text interface{}, data TemplateData) (string, error) {
 	}
 }
 
+// TemplateCondition is an input condition given as a template, which applies
+// when it renders a truthy value. The template delimiters may be omitted,
+// ie. `eq .Input.source "registry"`.
+type TemplateCondition string
+
+func (c TemplateCondition) Template() string {
+	if strings.Contains(string(c), "{{") {
+		return string(c)
+	}
+	return "{{ " + string(c) + " }}"
+}
+
+func (c TemplateCondition) Evaluate(values map[string]any) (bool, error) {
+	s, err := RenderTemplate(c.Template(), NewTemplateData(values, NewEnvMap(os.Environ())))
+	if err != nil {
+		return false, err
+	}
+	return isTruthy(s), nil
+}
+
+// TemplateOptions are input options whose labels and values are templates,
+// rendered with the values of the inputs before it.
+type TemplateOptions inputs.InputOptions
+
+func (o TemplateOptions) Options(values map[string]any) (inputs.InputOptions, error) {
+	data := NewTemplateData(values, NewEnvMap(os.Environ()))
+	options := make(inputs.InputOptions, 0, len(o))
+	for _, option := range o {
+		label, err := RenderTemplate(option.Label, data)
+		if err != nil {
+			return nil, err
+		}
+		value, err := RenderTemplate(option.Value, data)
+		if err != nil {
+			return nil, err
+		}
+		option.Label, option.Value = label, value
+		options = append(options, option)
+	}
+	return options, nil
+}
+
+// Templates returns the label and value templates of the options.
+func (o TemplateOptions) Templates() []string {
+	var templates []string
+	for _, option := range o {
+		templates = append(templates, option.Label, option.Value)
+	}
+	return templates
+}
+
+// TemplateDefault is the default value of an input given as a template,
+// rendered with the values of the inputs before it. Values of inputs not yet
+// given render as empty.
+type TemplateDefault string
+
+func (d TemplateDefault) Default(values map[string]any) (string, error) {
+	s, err := RenderTemplate(string(d), NewTemplateData(values, NewEnvMap(os.Environ())))
+	if err != nil {
+		return "", err
+	}
+	return strings.ReplaceAll(s, "<no value>", ""), nil
+}
+
+// RememberedDefault is the default value of an input remembered from the
+// last time it was given.
+type RememberedDefault string
+
+func (d RememberedDefault) Default(map[string]any) (string, error) {
+	return string(d), nil
+}
+
+// CommandOptions generates input options from the output of a shell command,
+// rendered with the values of the inputs before it. Each line of output is an
+// option, unless the output is a JSON array of strings or `{label, value}`
+// objects.
+type CommandOptions struct {
+	Command string
+	Timeout time.Duration
+}
+
+func (o CommandOptions) Options(values map[string]any) (inputs.InputOptions, error) {
+	output, err := runShell(o.Command, o.Timeout, NewTemplateData(values, NewEnvMap(os.Environ())), nil)
+	if err != nil {
+		return nil, err
+	}
+	return parseOptions(output)
+}
+
+// runShell renders the command template with the data and runs it in the
+// default shell with the extra environment variables, returning its output.
+// Errors include what the command wrote to stderr.
+func runShell(command string, timeout time.Duration, data TemplateData, env []string) ([]byte, error) {
+	script, err := RenderTemplate(command, data)
+	if err != nil {
+		return nil, err
+	}
+	if timeout <= 0 {
+		timeout = DefaultOptionsTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	args := append(append([]string{}, DefaultShell[1:]...), "-c", script)
+	cmd := exec.CommandContext(ctx, DefaultShell[0], args...)
+	if len(env) > 0 {
+		cmd.Env = append(os.Environ(), env...)
+	}
+	// Don't wait on children of the shell that outlive it
+	cmd.WaitDelay = 100 * time.Millisecond
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return nil, fmt.Errorf("command timed out after %s", timeout)
+	} else if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("%w: %s", err, msg)
+		}
+		return nil, err
+	}
+	return output, nil
+}
+
+// TemplateRule is a validation rule given as a template, which the value
+// passes when it renders a truthy value. The value is available as `.Value`
+// and the delimiters may be omitted like conditions, ie. `lt (len .Value) 50`.
+type TemplateRule struct {
+	Template string
+	Message  string
+}
+
+func (r TemplateRule) Check(value inputs.Value, values map[string]any) error {
+	data := NewTemplateData(values, NewEnvMap(os.Environ()))
+	data.Value = value.Get()
+	s, err := RenderTemplate(TemplateCondition(r.Template).Template(), data)
+	if err != nil {
+		return err
+	}
+	if !isTruthy(s) {
+		return ruleError(r.Message, fmt.Errorf("must satisfy %s", r.Template))
+	}
+	return nil
+}
+
+func (r TemplateRule) CheckLive(value inputs.Value, values map[string]any) error {
+	return r.Check(value, values)
+}
+
+// CommandRule is a validation rule given as a shell command, which the value
+// passes when the command exits successfully. The command is rendered like
+// options_from, with the value available as `.Value` and in the environment
+// variable ILC_VALUE.
+type CommandRule struct {
+	Command string
+	Timeout time.Duration
+	Message string
+}
+
+func (r CommandRule) Check(value inputs.Value, values map[string]any) error {
+	data := NewTemplateData(values, NewEnvMap(os.Environ()))
+	data.Value = value.Get()
+	if _, err := runShell(r.Command, r.Timeout, data, []string{EnvValue + "=" + value.String()}); err != nil {
+		return ruleError(r.Message, err)
+	}
+	return nil
+}
+
+// ruleError returns the message of a failed rule, or the error when it has
+// none.
+func ruleError(message string, err error) error {
+	if message != "" {
+		return errors.New(message)
+	}
+	return err
+}
+
+// StringTransform is a built-in transform of input values, one of trim,
+// lower, upper or slugify.
+type StringTransform string
+
+var stringTransforms = map[StringTransform]func(string) string{
+	"trim":    strings.TrimSpace,
+	"lower":   strings.ToLower,
+	"upper":   strings.ToUpper,
+	"slugify": slugify,
+}
+
+// IsStringTransform returns whether the name is of a built-in transform.
+func IsStringTransform(name string) bool {
+	_, ok := stringTransforms[StringTransform(name)]
+	return ok
+}
+
+func (t StringTransform) Transform(s string, _ map[string]any) (string, error) {
+	transform, ok := stringTransforms[t]
+	if !ok {
+		return "", fmt.Errorf("unknown transform %q", string(t))
+	}
+	return transform(s), nil
+}
+
+var slugPattern = regexp.MustCompile(`[^a-z0-9]+`)
+
+// slugify lowercases the string and replaces each run of characters other
+// than letters and digits with a hyphen, ie. "Hello, World" becomes
+// "hello-world".
+func slugify(s string) string {
+	return strings.Trim(slugPattern.ReplaceAllString(strings.ToLower(s), "-"), "-")
+}
+
+// ReplaceTransform replaces the matches of a regular expression in input
+// values, expanding `$1` and the like in the replacement.
+type ReplaceTransform struct {
+	Pattern string
+	With    string
+}
+
+func (t ReplaceTransform) Transform(s string, _ map[string]any) (string, error) {
+	re, err := regexp.Compile(t.Pattern)
+	if err != nil {
+		return "", err
+	}
+	return re.ReplaceAllString(s, t.With), nil
+}
+
+// TemplateTransform is a transform given as a template, whose output becomes
+// the value. The value is available as `.Value`, ie. `{{ .Value }}.example.com`.
+type TemplateTransform string
+
+func (t TemplateTransform) Transform(s string, values map[string]any) (string, error) {
+	data := NewTemplateData(values, NewEnvMap(os.Environ()))
+	data.Value = s
+	return RenderTemplate(string(t), data)
+}
+
+func parseOptions(output []byte) (inputs.InputOptions, error) {
+	var options inputs.InputOptions
+	trimmed := bytes.TrimSpace(output)
+	if bytes.HasPrefix(trimmed, []byte("[")) {
+		var items []json.RawMessage
+		if err := json.Unmarshal(trimmed, &items); err != nil {
+			return nil, fmt.Errorf("invalid options: %w", err)
+		}
+		for _, item := range items {
+			var value string
+			if err := json.Unmarshal(item, &value); err == nil {
+				options = append(options, inputs.InputOption{Label: value, Value: value})
+				continue
+			}
+			var option inputs.InputOption
+			if err := json.Unmarshal(item, &option); err != nil {
+				return nil, fmt.Errorf("invalid option %s: %w", item, err)
+			}
+			if option.Label == "" {
+				option.Label = option.Value
+			}
+			options = append(options, option)
+		}
+		return options, nil
+	}
+	for _, line := range strings.Split(string(trimmed), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			options = append(options, inputs.InputOption{Label: line, Value: line})
+		}
+	}
+	return options, nil
+}
+
+func isTruthy(s string) bool {
+	s = strings.TrimSpace(s)
+	if b, err := strconv.ParseBool(s); err == nil {
+		return b
+	}
+	return s != "" && s != "<no value>"
+}
+
 func DiffStrings(a, b []string) []string {
 	mb := make(map[string]struct{}, len(b))
 	for _, x := range b {
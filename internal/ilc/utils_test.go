@@ -1,11 +1,15 @@
 package ilc
 
 import (
+	"fmt"
 	"math"
 	"slices"
+	"sync"
 	"testing"
 	"text/template"
+	"time"
 
+	"github.com/evilmarty/ilc/internal/inputs"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -101,6 +105,21 @@ func TestRenderTemplate(t *testing.T) {
 		assert.Equal(t, expected, actual, "RenderTemplate() returned unexpected results")
 	})
 
+	t.Run("given strings concurrently", func(t *testing.T) {
+		var wg sync.WaitGroup
+		for i := range 1000 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				text := fmt.Sprintf("{{input \"foobar\"}}-%d", i)
+				actual, err := RenderTemplate(text, data)
+				assert.NoError(t, err, "RenderTemplate() returned unexpected error")
+				assert.Equal(t, fmt.Sprintf("a-%d", i), actual, "RenderTemplate() returned unexpected results")
+			}()
+		}
+		wg.Wait()
+	})
+
 	t.Run("given other", func(t *testing.T) {
 		_, actual := RenderTemplate(nil, data)
 		assert.EqualError(t, actual, "unsupported type: <nil>", "RenderTemplate() returned unexpected error")
@@ -149,3 +168,152 @@ func TestToFloat64(t *testing.T) {
 		assert.True(t, math.IsNaN(ToFloat64("1")), "toFloat64() returned unexpected result")
 	})
 }
+
+func TestTemplateCondition(t *testing.T) {
+	values := map[string]any{"source": "registry", "dry-run": true}
+	tests := []struct {
+		when     TemplateCondition
+		expected bool
+	}{
+		{`{{ eq .Input.source "registry" }}`, true},
+		{`eq .Input.source "local"`, false},
+		{`.Input.dry_run`, true},
+		{`.Input.missing`, false},
+		{`{{ if .Input.source }}yes{{ end }}`, true},
+		{`{{ if false }}yes{{ end }}`, false},
+	}
+	for _, tt := range tests {
+		actual, err := tt.when.Evaluate(values)
+		assert.NoError(t, err, string(tt.when))
+		assert.Equal(t, tt.expected, actual, string(tt.when))
+	}
+
+	_, err := TemplateCondition(`{{ eq }}`).Evaluate(values)
+	assert.Error(t, err)
+
+	t.Setenv("ILC_TEST_REGION", "eu")
+	actual, err := TemplateCondition(`eq .Env.ILC_TEST_REGION "eu"`).Evaluate(values)
+	assert.NoError(t, err)
+	assert.True(t, actual, "conditions see the environment like other templates")
+}
+
+func TestTemplateOptions(t *testing.T) {
+	options := TemplateOptions{
+		{Label: "api ({{ .Input.env }})", Value: "{{ .Input.env }}-api"},
+		{Label: "web", Value: "web"},
+	}
+	actual, err := options.Options(map[string]any{"env": "prod"})
+	assert.NoError(t, err)
+	assert.Equal(t, inputs.InputOptions{{Label: "api (prod)", Value: "prod-api"}, {Label: "web", Value: "web"}}, actual)
+
+	actual, err = TemplateOptions{{Label: "{{ .Input.env }}", Value: "x", Description: "Only in prod", Group: "Envs", Disabled: "Soon"}}.Options(map[string]any{"env": "prod"})
+	assert.NoError(t, err)
+	assert.Equal(t, inputs.InputOptions{{Label: "prod", Value: "x", Description: "Only in prod", Group: "Envs", Disabled: "Soon"}}, actual)
+
+	_, err = TemplateOptions{{Label: "{{ eq }}", Value: "x"}}.Options(nil)
+	assert.Error(t, err)
+}
+
+func TestTemplateDefault(t *testing.T) {
+	actual, err := TemplateDefault("{{ .Input.env }}-api").Default(map[string]any{"env": "prod"})
+	assert.NoError(t, err)
+	assert.Equal(t, "prod-api", actual)
+
+	actual, err = TemplateDefault("{{ .Input.env }}").Default(map[string]any{})
+	assert.NoError(t, err)
+	assert.Equal(t, "", actual)
+}
+
+func TestTemplateRule(t *testing.T) {
+	short := TemplateRule{Template: "lt (len .Value) 5"}
+	assert.NoError(t, short.Check(&inputs.StringValue{Value: "web"}, nil))
+	assert.EqualError(t, short.Check(&inputs.StringValue{Value: "website"}, nil), "must satisfy lt (len .Value) 5")
+
+	after := TemplateRule{Template: "{{ gt .Value .Input.start }}", Message: "must be after start"}
+	assert.NoError(t, after.CheckLive(&inputs.NumberValue{Value: 3}, map[string]any{"start": 2.0}))
+	assert.EqualError(t, after.Check(&inputs.NumberValue{Value: 1}, map[string]any{"start": 2.0}), "must be after start")
+}
+
+func TestCommandRule(t *testing.T) {
+	rule := CommandRule{Command: `test "$ILC_VALUE" = "{{ .Input.expected }}"`}
+	assert.NoError(t, rule.Check(&inputs.StringValue{Value: "web"}, map[string]any{"expected": "web"}))
+	assert.EqualError(t, rule.Check(&inputs.StringValue{Value: "api"}, map[string]any{"expected": "web"}), "exit status 1")
+
+	rule = CommandRule{Command: "echo not found >&2; exit 1"}
+	assert.EqualError(t, rule.Check(&inputs.StringValue{}, nil), "exit status 1: not found")
+	rule.Message = "must exist"
+	assert.EqualError(t, rule.Check(&inputs.StringValue{}, nil), "must exist")
+}
+
+func TestStringTransform(t *testing.T) {
+	tests := []struct {
+		transform StringTransform
+		input     string
+		expected  string
+	}{
+		{"trim", "  web \n", "web"},
+		{"lower", "Web", "web"},
+		{"upper", "Web", "WEB"},
+		{"slugify", " Hello, World! ", "hello-world"},
+		{"slugify", "feature/ABC_123", "feature-abc-123"},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.transform), func(t *testing.T) {
+			actual, err := tt.transform.Transform(tt.input, nil)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, actual)
+		})
+	}
+	_, err := StringTransform("reverse").Transform("web", nil)
+	assert.EqualError(t, err, `unknown transform "reverse"`)
+}
+
+func TestReplaceTransform(t *testing.T) {
+	actual, err := ReplaceTransform{Pattern: `^v(\d+)`, With: "release-$1"}.Transform("v12.1", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "release-12.1", actual)
+}
+
+func TestTemplateTransform(t *testing.T) {
+	actual, err := TemplateTransform("{{ .Value }}.{{ .Input.env }}.example.com").Transform("web", map[string]any{"env": "prod"})
+	assert.NoError(t, err)
+	assert.Equal(t, "web.prod.example.com", actual)
+}
+
+func TestCommandOptions(t *testing.T) {
+	t.Run("lines", func(t *testing.T) {
+		options, err := CommandOptions{Command: "printf 'main\\n\\n{{ .Input.prefix }}-feature\\n'"}.Options(map[string]any{"prefix": "fix"})
+		assert.NoError(t, err)
+		assert.Equal(t, inputs.InputOptions{{Label: "main", Value: "main"}, {Label: "fix-feature", Value: "fix-feature"}}, options)
+	})
+
+	t.Run("json", func(t *testing.T) {
+		options, err := CommandOptions{Command: `echo '["main", {"label": "Feature", "value": "feature"}, {"value": "fix"}]'`}.Options(nil)
+		assert.NoError(t, err)
+		assert.Equal(t, inputs.InputOptions{{Label: "main", Value: "main"}, {Label: "Feature", Value: "feature"}, {Label: "fix", Value: "fix"}}, options)
+	})
+
+	t.Run("json entries", func(t *testing.T) {
+		options, err := CommandOptions{Command: `echo '[{"value": "main", "description": "Default branch", "group": "Long-lived"}, {"value": "gone", "disabled": "Deleted"}]'`}.Options(nil)
+		assert.NoError(t, err)
+		assert.Equal(t, inputs.InputOptions{
+			{Label: "main", Value: "main", Description: "Default branch", Group: "Long-lived"},
+			{Label: "gone", Value: "gone", Disabled: "Deleted"},
+		}, options)
+	})
+
+	t.Run("invalid json", func(t *testing.T) {
+		_, err := CommandOptions{Command: `echo '[1'`}.Options(nil)
+		assert.ErrorContains(t, err, "invalid options")
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		_, err := CommandOptions{Command: "echo oops >&2; exit 3"}.Options(nil)
+		assert.EqualError(t, err, "exit status 3: oops")
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		_, err := CommandOptions{Command: "sleep 1", Timeout: 10 * time.Millisecond}.Options(nil)
+		assert.EqualError(t, err, "command timed out after 10ms")
+	})
+}
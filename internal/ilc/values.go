@@ -0,0 +1,102 @@
+package ilc
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/evilmarty/ilc/internal/inputs"
+	"gopkg.in/yaml.v3"
+)
+
+// StdioPath reads from stdin, or writes to stdout, in place of a file.
+const StdioPath = "-"
+
+var ErrInvalidValues = errors.New("invalid input values")
+
+// ReadValues reads input values keyed by input name from YAML or JSON. The
+// values of list inputs are given as sequences.
+func ReadValues(r io.Reader) (map[string]string, error) {
+	var doc yaml.Node
+	if err := yaml.NewDecoder(r).Decode(&doc); errors.Is(err, io.EOF) {
+		return map[string]string{}, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidValues, err)
+	}
+	values, err := decodeValues(doc.Content[0])
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidValues, err)
+	}
+	return values, nil
+}
+
+// decodeValues decodes a mapping of input names to values, joining the items
+// of sequences into list values.
+func decodeValues(node *yaml.Node) (map[string]string, error) {
+	values := map[string]string{}
+	if node.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("line %d: expected a mapping of input names to values", node.Line)
+	}
+	for i := 0; i < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		switch value.Kind {
+		case yaml.ScalarNode:
+			values[key.Value] = scalarValue(value)
+		case yaml.SequenceNode:
+			items := make([]string, 0, len(value.Content))
+			for _, item := range value.Content {
+				if item.Kind != yaml.ScalarNode {
+					return nil, fmt.Errorf("line %d: items of input %s must be values", item.Line, key.Value)
+				}
+				items = append(items, scalarValue(item))
+			}
+			values[key.Value] = strings.Join(items, inputs.DefaultListSeparator)
+		default:
+			return nil, fmt.Errorf("line %d: input %s must be a value or a list", value.Line, key.Value)
+		}
+	}
+	return values, nil
+}
+
+func scalarValue(node *yaml.Node) string {
+	if node.ShortTag() == "!!null" {
+		return ""
+	}
+	return node.Value
+}
+
+// WriteValues writes input values as JSON when the path is stdout or ends in
+// .json, and as YAML otherwise, so they can be read back with ReadValues.
+func WriteValues(w io.Writer, path string, values map[string]any) error {
+	if path == StdioPath || strings.EqualFold(filepath.Ext(path), ".json") {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(values)
+	}
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(values); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
+
+// CheckValues returns an error naming the values that are not for any of the
+// inputs.
+func CheckValues(inps Inputs, values map[string]string) error {
+	var unknown []string
+	for name := range values {
+		if !inps.Has(name) {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) == 0 {
+		return nil
+	}
+	slices.Sort(unknown)
+	return fmt.Errorf("%w: unknown inputs: %s", ErrInvalidValues, strings.Join(unknown, ", "))
+}
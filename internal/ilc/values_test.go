@@ -0,0 +1,79 @@
+package ilc
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/evilmarty/ilc/internal/inputs"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadValues(t *testing.T) {
+	t.Run("yaml", func(t *testing.T) {
+		content := `
+name: web
+replicas: 3
+debug: true
+services: [api, web]
+notes: ~
+`
+		values, err := ReadValues(strings.NewReader(content))
+		assert.NoError(t, err)
+		expected := map[string]string{"name": "web", "replicas": "3", "debug": "true", "services": "api,web", "notes": ""}
+		assert.Equal(t, expected, values)
+	})
+
+	t.Run("json", func(t *testing.T) {
+		values, err := ReadValues(strings.NewReader(`{"name": "web", "ratio": 0.5, "services": ["api"]}`))
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]string{"name": "web", "ratio": "0.5", "services": "api"}, values)
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		values, err := ReadValues(strings.NewReader(""))
+		assert.NoError(t, err)
+		assert.Empty(t, values)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := ReadValues(strings.NewReader("[a, b]"))
+		assert.ErrorIs(t, err, ErrInvalidValues)
+		assert.ErrorContains(t, err, "expected a mapping of input names to values")
+		_, err = ReadValues(strings.NewReader("name:\n  first: a\n"))
+		assert.ErrorContains(t, err, "input name must be a value or a list")
+		_, err = ReadValues(strings.NewReader("services: [[a]]"))
+		assert.ErrorContains(t, err, "items of input services must be values")
+		_, err = ReadValues(strings.NewReader("{"))
+		assert.ErrorIs(t, err, ErrInvalidValues)
+	})
+}
+
+func TestWriteValues(t *testing.T) {
+	values := map[string]any{"name": "web", "replicas": int64(3), "services": []string{"api", "web"}}
+
+	var jsonBuf bytes.Buffer
+	assert.NoError(t, WriteValues(&jsonBuf, "values.json", values))
+	assert.Equal(t, "{\n  \"name\": \"web\",\n  \"replicas\": 3,\n  \"services\": [\n    \"api\",\n    \"web\"\n  ]\n}\n", jsonBuf.String())
+
+	var yamlBuf bytes.Buffer
+	assert.NoError(t, WriteValues(&yamlBuf, "values.yml", values))
+	assert.Equal(t, "name: web\nreplicas: 3\nservices:\n  - api\n  - web\n", yamlBuf.String())
+
+	// Both read back the same
+	for _, buf := range []*bytes.Buffer{&jsonBuf, &yamlBuf} {
+		read, err := ReadValues(buf)
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]string{"name": "web", "replicas": "3", "services": "api,web"}, read)
+	}
+}
+
+func TestCheckValues(t *testing.T) {
+	fs := inputs.NewFlagSet("ilc", EnvVarPrefix)
+	fs.Var(&inputs.Input{Name: "name", Value: &inputs.StringValue{}})
+	inps := Inputs{FlagSet: fs}
+	assert.NoError(t, CheckValues(inps, map[string]string{"name": "web"}))
+	err := CheckValues(inps, map[string]string{"name": "web", "nmae": "web", "env": "prod"})
+	assert.ErrorIs(t, err, ErrInvalidValues)
+	assert.EqualError(t, err, "invalid input values: unknown inputs: env, nmae")
+}
@@ -1,9 +1,11 @@
 package ilc
 
 import (
+	"cmp"
 	"fmt"
 	"regexp"
 	"strings"
+	"time"
 
 	"github.com/evilmarty/ilc/internal/inputs"
 	orderedmap "github.com/wk8/go-ordered-map/v2"
@@ -65,21 +67,212 @@ type yamlInputOptions inputs.InputOptions
 
 func (x *yamlInputOptions) UnmarshalYAML(node *yaml.Node) error {
 	if node.Kind == yaml.SequenceNode {
-		var values []string
-		if err := node.Decode(&values); err != nil {
-			return err
-		}
-		for _, value := range values {
-			*x = append(*x, inputs.InputOption{Label: value, Value: value})
+		for _, item := range node.Content {
+			option, err := decodeInputOption(item, "")
+			if err != nil {
+				return err
+			}
+			*x = append(*x, option)
 		}
 	} else {
-		om := orderedmap.New[string, string]()
+		om := orderedmap.New[string, yaml.Node]()
 		if err := node.Decode(&om); err != nil {
 			return err
 		}
 		for pair := om.Oldest(); pair != nil; pair = pair.Next() {
-			*x = append(*x, inputs.InputOption{Label: pair.Key, Value: pair.Value})
+			option, err := decodeInputOption(&pair.Value, pair.Key)
+			if err != nil {
+				return err
+			}
+			*x = append(*x, option)
+		}
+	}
+	return nil
+}
+
+// decodeInputOption decodes an option given as its value, or as a mapping with
+// its label, value, description, group and the reason it's disabled. Options
+// keyed by their label in the map form are given it as label.
+func decodeInputOption(node *yaml.Node, label string) (inputs.InputOption, error) {
+	var option inputs.InputOption
+	switch node.Kind {
+	case yaml.ScalarNode:
+		if err := node.Decode(&option.Value); err != nil {
+			return option, err
+		}
+	case yaml.MappingNode:
+		for i := 0; i < len(node.Content); i += 2 {
+			if key, value := node.Content[i], node.Content[i+1]; key.Value == "disabled" && value.ShortTag() != "!!str" {
+				return option, fmt.Errorf("line %d: disabled must be the reason the option is disabled", value.Line)
+			}
+		}
+		if err := node.Decode(&option); err != nil {
+			return option, err
+		}
+		if option.Label == "" && option.Value == "" && label == "" {
+			return option, fmt.Errorf("line %d: option must have a label or a value", node.Line)
+		} else if option.Value == "" {
+			option.Value = cmp.Or(label, option.Label)
+		}
+	default:
+		return option, fmt.Errorf("line %d: option must be a value or a mapping", node.Line)
+	}
+	option.Label = cmp.Or(label, option.Label, option.Value)
+	return option, nil
+}
+
+type yamlCommandOptions CommandOptions
+
+func (x *yamlCommandOptions) UnmarshalYAML(node *yaml.Node) error {
+	options, err := decodeCommandOptions(node, "options_from")
+	if err != nil {
+		return err
+	}
+	*x = yamlCommandOptions(options)
+	return nil
+}
+
+// yamlCommandSuggestions generates the suggestions of an input like
+// options_from does its options.
+type yamlCommandSuggestions CommandOptions
+
+func (x *yamlCommandSuggestions) UnmarshalYAML(node *yaml.Node) error {
+	options, err := decodeCommandOptions(node, "suggestions_from")
+	if err != nil {
+		return err
+	}
+	*x = yamlCommandSuggestions(options)
+	return nil
+}
+
+// decodeCommandOptions decodes a command, or a mapping of the command and its
+// timeout, given for the key.
+func decodeCommandOptions(node *yaml.Node, key string) (CommandOptions, error) {
+	var options CommandOptions
+	if node.Kind == yaml.ScalarNode {
+		options.Command = node.Value
+	} else {
+		var temp struct {
+			Command string        `yaml:"command"`
+			Timeout time.Duration `yaml:"timeout"`
+		}
+		if err := node.Decode(&temp); err != nil {
+			return options, err
+		}
+		options = CommandOptions(temp)
+	}
+	if strings.TrimSpace(options.Command) == "" {
+		return options, fmt.Errorf("line %d: %s must have a command", node.Line, key)
+	}
+	if options.Timeout < 0 {
+		return options, fmt.Errorf("line %d: %s timeout must not be negative", node.Line, key)
+	}
+	return options, nil
+}
+
+type presetName string
+
+func (x *presetName) UnmarshalYAML(node *yaml.Node) error {
+	var s string
+	if err := node.Decode(&s); err != nil {
+		return err
+	}
+	if !validName(s) {
+		return fmt.Errorf("line %d: invalid preset name", node.Line)
+	}
+	*x = presetName(s)
+	return nil
+}
+
+func (x *Presets) UnmarshalYAML(node *yaml.Node) error {
+	om := orderedmap.New[presetName, yaml.Node]()
+	if err := node.Decode(&om); err != nil {
+		return err
+	}
+	for pair := om.Oldest(); pair != nil; pair = pair.Next() {
+		values, err := decodeValues(&pair.Value)
+		if err != nil {
+			return fmt.Errorf("invalid preset %s: %w", pair.Key, err)
+		}
+		*x = append(*x, Preset{Name: string(pair.Key), Values: values})
+	}
+	return nil
+}
+
+// yamlRule is a validation rule given as a template, or a mapping of either
+// a template or a command and the message shown when the value fails it.
+type yamlRule struct {
+	Rule inputs.Rule
+}
+
+func (x *yamlRule) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		x.Rule = TemplateRule{Template: strings.TrimSpace(node.Value)}
+		return nil
+	}
+	var temp struct {
+		Template string        `yaml:"template"`
+		Command  string        `yaml:"command"`
+		Timeout  time.Duration `yaml:"timeout"`
+		Message  string        `yaml:"message"`
+	}
+	if err := node.Decode(&temp); err != nil {
+		return err
+	}
+	temp.Template = strings.TrimSpace(temp.Template)
+	temp.Message = strings.TrimSpace(temp.Message)
+	switch {
+	case temp.Template != "" && strings.TrimSpace(temp.Command) != "":
+		return fmt.Errorf("line %d: validate rule cannot have both a template and a command", node.Line)
+	case temp.Template != "":
+		x.Rule = TemplateRule{Template: temp.Template, Message: temp.Message}
+	case strings.TrimSpace(temp.Command) != "":
+		if temp.Timeout < 0 {
+			return fmt.Errorf("line %d: validate rule timeout must not be negative", node.Line)
+		}
+		x.Rule = CommandRule{Command: temp.Command, Timeout: temp.Timeout, Message: temp.Message}
+	default:
+		return fmt.Errorf("line %d: validate rule must have a template or a command", node.Line)
+	}
+	return nil
+}
+
+// yamlTransform is a transform of input values given as the name of a
+// built-in transform, or a mapping of either replace or template.
+type yamlTransform struct {
+	Transform inputs.Transform
+}
+
+func (x *yamlTransform) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		if !IsStringTransform(node.Value) {
+			return fmt.Errorf("line %d: unknown transform %q", node.Line, node.Value)
+		}
+		x.Transform = StringTransform(node.Value)
+		return nil
+	}
+	var temp struct {
+		Replace *struct {
+			Pattern string `yaml:"pattern"`
+			With    string `yaml:"with"`
+		} `yaml:"replace"`
+		Template string `yaml:"template"`
+	}
+	if err := node.Decode(&temp); err != nil {
+		return err
+	}
+	switch {
+	case temp.Replace != nil && temp.Template != "":
+		return fmt.Errorf("line %d: transform cannot have both replace and a template", node.Line)
+	case temp.Replace != nil:
+		if _, err := regexp.Compile(temp.Replace.Pattern); err != nil {
+			return fmt.Errorf("line %d: invalid replace pattern: %w", node.Line, err)
 		}
+		x.Transform = ReplaceTransform{Pattern: temp.Replace.Pattern, With: temp.Replace.With}
+	case temp.Template != "":
+		x.Transform = TemplateTransform(temp.Template)
+	default:
+		return fmt.Errorf("line %d: transform must be a name, replace or a template", node.Line)
 	}
 	return nil
 }
@@ -106,18 +299,44 @@ func (y yamlInputType) newValue() inputs.Value {
 	switch y.Type {
 	case "number":
 		return &inputs.NumberValue{}
+	case "integer":
+		return &inputs.IntegerValue{}
 	case "boolean":
 		return &inputs.BooleanValue{}
+	case "list":
+		return &inputs.ListValue{}
+	case "path":
+		return &inputs.PathValue{}
+	case "date":
+		return &inputs.DateValue{}
+	case "datetime":
+		return &inputs.DateValue{WithTime: true}
+	case "duration":
+		return &inputs.DurationValue{}
 	default:
 		return &inputs.StringValue{}
 	}
 }
 
 type yamlInput struct {
-	Name        string
-	Description string
-	Options     yamlInputOptions
-	Value       inputs.Value
+	Name            string
+	Description     string
+	Options         yamlInputOptions
+	AllowOther      bool
+	Suggestions     []string
+	Short           string
+	Position        int
+	Optional        bool
+	Secret          bool
+	Env             string
+	Remember        *bool
+	When            TemplateCondition
+	OptionsFrom     inputs.OptionsSource
+	SuggestionsFrom inputs.OptionsSource
+	Default         TemplateDefault
+	Rules           []inputs.Rule
+	Transforms      []inputs.Transform
+	Value           inputs.Value
 }
 
 func (x *yamlInput) UnmarshalYAML(node *yaml.Node) error {
@@ -131,20 +350,137 @@ func (x *yamlInput) UnmarshalYAML(node *yaml.Node) error {
 	val := inputType.newValue()
 
 	type tempInput struct {
-		Description string           `yaml:"description"`
-		Options     yamlInputOptions `yaml:"options,flow"`
+		Description     string                  `yaml:"description"`
+		Options         yamlInputOptions        `yaml:"options,flow"`
+		Short           string                  `yaml:"short"`
+		Positional      int                     `yaml:"positional"`
+		Required        *bool                   `yaml:"required"`
+		When            string                  `yaml:"when"`
+		Secret          bool                    `yaml:"secret"`
+		Env             string                  `yaml:"env"`
+		Remember        *bool                   `yaml:"remember"`
+		OptionsFrom     *yamlCommandOptions     `yaml:"options_from"`
+		AllowOther      bool                    `yaml:"allow_other"`
+		Suggestions     []string                `yaml:"suggestions"`
+		SuggestionsFrom *yamlCommandSuggestions `yaml:"suggestions_from"`
+		Validate        []yamlRule              `yaml:"validate"`
+		Transform       []yamlTransform         `yaml:"transform"`
 	}
 	var temp tempInput
+	var defaultTemplate TemplateDefault
+	hasDefault := false
+	var defaultNode *yaml.Node
 
 	if node.Kind == yaml.MappingNode {
 		if err := node.Decode(&temp); err != nil {
 			return err
 		}
-		if err := node.Decode(val); err != nil {
+		valueNode := *node
+		for i := 0; i < len(node.Content); i += 2 {
+			if key, value := node.Content[i], node.Content[i+1]; key.Value == "default" {
+				hasDefault = true
+				defaultNode = value
+				// Templated defaults are rendered later, so leave them out of the value
+				if value.Kind == yaml.ScalarNode && strings.Contains(value.Value, "{{") {
+					defaultTemplate = TemplateDefault(value.Value)
+					valueNode.Content = append(append([]*yaml.Node{}, node.Content[:i]...), node.Content[i+2:]...)
+				}
+				break
+			}
+		}
+		if err := valueNode.Decode(val); err != nil {
 			return err
 		}
 	}
 
+	if temp.Short != "" && !validShortName(temp.Short) {
+		return fmt.Errorf("line %d: input short name must be a single letter", node.Line)
+	}
+
+	if temp.Env != "" && !validEnvName(temp.Env) {
+		return fmt.Errorf("line %d: input env %q is not a valid environment variable name", node.Line, temp.Env)
+	}
+
+	if len(temp.Transform) > 0 {
+		switch val.(type) {
+		case *inputs.StringValue, *inputs.ListValue:
+		default:
+			return fmt.Errorf("line %d: only string and list inputs can be transformed", node.Line)
+		}
+	}
+
+	if temp.OptionsFrom != nil && len(temp.Options) > 0 {
+		return fmt.Errorf("line %d: input cannot have both options and options_from", node.Line)
+	}
+
+	if temp.AllowOther {
+		if len(temp.Options) == 0 && temp.OptionsFrom == nil {
+			return fmt.Errorf("line %d: input must have options to allow other values", node.Line)
+		}
+		switch val.(type) {
+		case *inputs.BooleanValue, *inputs.ListValue:
+			return fmt.Errorf("line %d: only inputs with a single option chosen can allow other values", node.Line)
+		}
+	}
+
+	if len(temp.Suggestions) > 0 || temp.SuggestionsFrom != nil {
+		if _, ok := val.(*inputs.StringValue); !ok {
+			return fmt.Errorf("line %d: only string inputs can have suggestions", node.Line)
+		}
+		if temp.OptionsFrom != nil || len(temp.Options) > 0 {
+			return fmt.Errorf("line %d: input cannot have both options and suggestions", node.Line)
+		}
+	}
+
+	switch v := val.(type) {
+	case *inputs.StringValue:
+		if v.MinLength < 0 || v.MaxLength < 0 || (v.MaxLength > 0 && v.MinLength > v.MaxLength) {
+			return fmt.Errorf("line %d: string input min_length %d and max_length %d are out of range", node.Line, v.MinLength, v.MaxLength)
+		}
+		if v.Format != "" && !inputs.IsStringFormat(v.Format) {
+			return fmt.Errorf("line %d: unknown string input format %q", node.Line, v.Format)
+		}
+	case *inputs.NumberValue:
+		if v.Step < 0 {
+			return fmt.Errorf("line %d: input step must be greater than zero", node.Line)
+		}
+		if v.Precision != nil && *v.Precision < 0 {
+			return fmt.Errorf("line %d: input precision must not be negative", node.Line)
+		}
+	case *inputs.IntegerValue:
+		if v.Step < 0 {
+			return fmt.Errorf("line %d: input step must be greater than zero", node.Line)
+		}
+		// Decoding silently truncates floats, so reject them up front
+		if defaultNode != nil && defaultTemplate == "" && defaultNode.ShortTag() == "!!float" {
+			return fmt.Errorf("line %d: integer input default must be a whole number", defaultNode.Line)
+		}
+	case *inputs.PathValue:
+		if v.Kind != "" && v.Kind != inputs.PathKindFile && v.Kind != inputs.PathKindDir {
+			return fmt.Errorf("line %d: path input kind must be %s or %s", node.Line, inputs.PathKindFile, inputs.PathKindDir)
+		}
+	case *inputs.DateValue, *inputs.DurationValue:
+		// Their defaults can be relative, so are set rather than decoded
+		if err := checkBounds(val, node.Line); err != nil {
+			return err
+		}
+		if defaultNode != nil && defaultTemplate == "" {
+			if err := val.Set(defaultNode.Value); err != nil {
+				return fmt.Errorf("line %d: invalid input default %q: %w", defaultNode.Line, defaultNode.Value, err)
+			}
+		}
+	}
+
+	if list, isList := val.(*inputs.ListValue); isList {
+		if list.MinItems < 0 || list.MaxItems < 0 || (list.MaxItems > 0 && list.MinItems > list.MaxItems) {
+			return fmt.Errorf("line %d: list input min_items %d and max_items %d are out of range", node.Line, list.MinItems, list.MaxItems)
+		}
+	}
+
+	if temp.Positional < 0 {
+		return fmt.Errorf("line %d: input position must be greater than zero", node.Line)
+	}
+
 	if _, isBool := val.(*inputs.BooleanValue); isBool && len(temp.Options) > 0 {
 		var optionsNode *yaml.Node
 		for i := 0; i < len(node.Content); i += 2 {
@@ -159,8 +495,8 @@ func (x *yamlInput) UnmarshalYAML(node *yaml.Node) error {
 				if len(temp.Options) != 2 {
 					return fmt.Errorf("line %d: boolean input options array must have exactly 2 items, got %d", optionsNode.Line, len(temp.Options))
 				}
-				temp.Options[0] = inputs.InputOption{Label: temp.Options[0].Label, Value: "false"}
-				temp.Options[1] = inputs.InputOption{Label: temp.Options[1].Label, Value: "true"}
+				temp.Options[0].Value = "false"
+				temp.Options[1].Value = "true"
 			} else {
 				hasTrue := false
 				hasFalse := false
@@ -169,24 +505,13 @@ func (x *yamlInput) UnmarshalYAML(node *yaml.Node) error {
 					key := opt.Label
 					valStr := opt.Value
 					if key == "true" || key == "false" {
-						if key == "true" {
-							hasTrue = true
-							newOptions = append(newOptions, inputs.InputOption{Label: valStr, Value: "true"})
-						} else {
-							hasFalse = true
-							newOptions = append(newOptions, inputs.InputOption{Label: valStr, Value: "false"})
-						}
-					} else if valStr == "true" || valStr == "false" {
-						if valStr == "true" {
-							hasTrue = true
-							newOptions = append(newOptions, inputs.InputOption{Label: key, Value: "true"})
-						} else {
-							hasFalse = true
-							newOptions = append(newOptions, inputs.InputOption{Label: key, Value: "false"})
-						}
-					} else {
+						opt.Label, opt.Value = valStr, key
+					} else if valStr != "true" && valStr != "false" {
 						return fmt.Errorf("line %d: invalid boolean option: key='%s', value='%s' (one must be true or false)", optionsNode.Line, key, valStr)
 					}
+					hasTrue = hasTrue || opt.Value == "true"
+					hasFalse = hasFalse || opt.Value == "false"
+					newOptions = append(newOptions, opt)
 				}
 				if !hasTrue || !hasFalse {
 					return fmt.Errorf("line %d: boolean option map must contain both true and false keys", optionsNode.Line)
@@ -198,6 +523,36 @@ func (x *yamlInput) UnmarshalYAML(node *yaml.Node) error {
 
 	x.Description = strings.TrimSpace(temp.Description)
 	x.Options = temp.Options
+	x.AllowOther = temp.AllowOther
+	x.Suggestions = temp.Suggestions
+	if temp.SuggestionsFrom != nil {
+		x.SuggestionsFrom = CommandOptions(*temp.SuggestionsFrom)
+	}
+	x.When = TemplateCondition(strings.TrimSpace(temp.When))
+	if temp.OptionsFrom != nil {
+		x.OptionsFrom = CommandOptions(*temp.OptionsFrom)
+	} else if _, isBool := val.(*inputs.BooleanValue); !isBool && hasTemplateOptions(temp.Options) {
+		x.OptionsFrom = TemplateOptions(temp.Options)
+		x.Options = nil
+	}
+	x.Default = defaultTemplate
+	x.Secret = temp.Secret
+	x.Env = temp.Env
+	x.Remember = temp.Remember
+	for _, rule := range temp.Validate {
+		x.Rules = append(x.Rules, rule.Rule)
+	}
+	for _, transform := range temp.Transform {
+		x.Transforms = append(x.Transforms, transform.Transform)
+	}
+	x.Short = temp.Short
+	x.Position = temp.Positional
+	// Inputs are required unless they have a default, or say otherwise
+	if temp.Required != nil {
+		x.Optional = !*temp.Required
+	} else {
+		x.Optional = hasDefault
+	}
 	x.Value = val
 	return nil
 }
@@ -208,8 +563,29 @@ func (x *Inputs) UnmarshalYAML(node *yaml.Node) error {
 		return err
 	}
 	fs := inputs.NewFlagSet("ilc", EnvVarPrefix)
+	positions := make(map[int]string)
+	shorts := make(map[string]string)
+	envs := make(map[string]string)
 	for pair := om.Oldest(); pair != nil; pair = pair.Next() {
 		pair.Value.Name = string(pair.Key)
+		if position := pair.Value.Position; position > 0 {
+			if other, found := positions[position]; found {
+				return fmt.Errorf("line %d: inputs %s and %s share position %d", node.Line, other, pair.Value.Name, position)
+			}
+			positions[position] = pair.Value.Name
+		}
+		if short := pair.Value.Short; short != "" {
+			if other, found := shorts[short]; found {
+				return fmt.Errorf("line %d: inputs %s and %s share short name %s", node.Line, other, pair.Value.Name, short)
+			}
+			shorts[short] = pair.Value.Name
+		}
+		if env := pair.Value.Env; env != "" {
+			if other, found := envs[env]; found {
+				return fmt.Errorf("line %d: inputs %s and %s share env %s", node.Line, other, pair.Value.Name, env)
+			}
+			envs[env] = pair.Value.Name
+		}
 		if pair.Value.Value == nil {
 			pair.Value.Value = &inputs.StringValue{}
 		}
@@ -217,15 +593,82 @@ func (x *Inputs) UnmarshalYAML(node *yaml.Node) error {
 			Name:        pair.Value.Name,
 			Description: pair.Value.Description,
 			Options:     inputs.InputOptions(pair.Value.Options),
+			AllowOther:  pair.Value.AllowOther,
+			Suggestions: pair.Value.Suggestions,
+			Short:       pair.Value.Short,
+			Position:    pair.Value.Position,
+			Optional:    pair.Value.Optional,
+			Secret:      pair.Value.Secret,
+			Env:         pair.Value.Env,
+			Remember:    pair.Value.Remember,
+			Rules:       pair.Value.Rules,
+			Transforms:  pair.Value.Transforms,
 			Value:       pair.Value.Value,
 		}
+		if pair.Value.When != "" {
+			inp.When = pair.Value.When
+		}
+		if pair.Value.OptionsFrom != nil {
+			inp.OptionsFrom = pair.Value.OptionsFrom
+		}
+		if pair.Value.SuggestionsFrom != nil {
+			inp.SuggestionsFrom = pair.Value.SuggestionsFrom
+		}
+		if pair.Value.Default != "" {
+			inp.DefaultFrom = pair.Value.Default
+		}
 		fs.Var(&inp)
 	}
 	*x = Inputs{FlagSet: fs}
 	return nil
 }
 
+// checkBounds reports an error when the bounds of a date or duration cannot be
+// read, or the min is after the max.
+func checkBounds(val inputs.Value, line int) error {
+	var temp inputs.Value
+	var bounds []string
+	switch v := val.(type) {
+	case *inputs.DateValue:
+		temp = &inputs.DateValue{Format: v.Format, MinValue: v.MinValue, MaxValue: v.MaxValue, WithTime: v.WithTime}
+		bounds = []string{v.MinValue, v.MaxValue}
+	case *inputs.DurationValue:
+		if _, err := inputs.ParseDuration(v.Step); v.Step != "" && err != nil {
+			return fmt.Errorf("line %d: invalid input step %q: %w", line, v.Step, err)
+		}
+		temp = &inputs.DurationValue{MinValue: v.MinValue, MaxValue: v.MaxValue}
+		bounds = []string{v.MinValue, v.MaxValue}
+	}
+	for i, name := range []string{"min", "max"} {
+		if bound := bounds[i]; bound != "" {
+			if err := temp.Set(bound); err != nil {
+				return fmt.Errorf("line %d: invalid input %s %q: %w", line, name, bound, err)
+			}
+		}
+	}
+	return nil
+}
+
+func hasTemplateOptions(options yamlInputOptions) bool {
+	for _, option := range options {
+		if strings.Contains(option.Label, "{{") || strings.Contains(option.Value, "{{") {
+			return true
+		}
+	}
+	return false
+}
+
 func validName(s string) bool {
 	m, _ := regexp.MatchString("^[a-zA-Z0-9][a-zA-Z0-9-_]*$", s)
 	return m
 }
+
+func validEnvName(s string) bool {
+	m, _ := regexp.MatchString("^[a-zA-Z_][a-zA-Z0-9_]*$", s)
+	return m
+}
+
+func validShortName(s string) bool {
+	m, _ := regexp.MatchString("^[a-zA-Z]$", s)
+	return m
+}
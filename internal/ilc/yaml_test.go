@@ -2,6 +2,7 @@ package ilc
 
 import (
 	"testing"
+	"time"
 
 	"github.com/evilmarty/ilc/internal/inputs"
 	"github.com/stretchr/testify/assert"
@@ -124,6 +125,33 @@ b: B
 	})
 }
 
+func TestPresetsUnmarshalYAML(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		content := `
+staging-canary:
+  env: staging
+  canary: true
+prod-full:
+  env: prod
+  regions: [us, eu]
+`
+		var actual Presets
+		err := yaml.Unmarshal([]byte(content), &actual)
+		assert.NoError(t, err)
+		expected := Presets{
+			{Name: "staging-canary", Values: map[string]string{"env": "staging", "canary": "true"}},
+			{Name: "prod-full", Values: map[string]string{"env": "prod", "regions": "us,eu"}},
+		}
+		assert.Equal(t, expected, actual)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		var actual Presets
+		assert.ErrorContains(t, yaml.Unmarshal([]byte("bad name:\n  env: prod\n"), &actual), "line 1: invalid preset name")
+		assert.ErrorContains(t, yaml.Unmarshal([]byte("prod: [env]\n"), &actual), "invalid preset prod: line 1: expected a mapping of input names to values")
+	})
+}
+
 func TestInputsUnmarshalYAML(t *testing.T) {
 	t.Run("valid", func(t *testing.T) {
 		content := `
@@ -153,18 +181,19 @@ boolean:
 			fs := inputs.NewFlagSet("ilc", EnvVarPrefix)
 			fs.Var(&inputs.Input{Name: "inline_string", Value: &inputs.StringValue{}})
 			fs.Var(&inputs.Input{
-				Name:  "string",
-				Value: &inputs.StringValue{Value: "foobar", Pattern: "^foo"},
+				Name:     "string",
+				Optional: true,
+				Value:    &inputs.StringValue{Value: "foobar", Pattern: "^foo"},
 				Options: inputs.InputOptions{
 					{Label: "a", Value: "foobar"},
 					{Label: "b", Value: "foobaz"},
 				},
 			})
-			fs.Var(&inputs.Input{Name: "default_string", Value: &inputs.StringValue{Value: "foobaz", Pattern: "^foo"}})
+			fs.Var(&inputs.Input{Name: "default_string", Optional: true, Value: &inputs.StringValue{Value: "foobaz", Pattern: "^foo"}})
 			fs.Var(&inputs.Input{Name: "inline_number", Value: &inputs.NumberValue{}})
-			fs.Var(&inputs.Input{Name: "number", Value: &inputs.NumberValue{Value: 1.0, MinValue: 1.0, MaxValue: 2.0}})
+			fs.Var(&inputs.Input{Name: "number", Optional: true, Value: &inputs.NumberValue{Value: 1.0, MinValue: 1.0, MaxValue: 2.0}})
 			fs.Var(&inputs.Input{Name: "inline_boolean", Value: &inputs.BooleanValue{}})
-			fs.Var(&inputs.Input{Name: "boolean", Value: &inputs.BooleanValue{Value: true}})
+			fs.Var(&inputs.Input{Name: "boolean", Optional: true, Value: &inputs.BooleanValue{Value: true}})
 			return Inputs{FlagSet: fs}
 		}()
 		var actual Inputs
@@ -305,3 +334,521 @@ bool_invalid_array:
 		assert.ErrorContains(t, err, "boolean input options array must have exactly 2 items, got 3")
 	})
 }
+
+func TestInputsUnmarshalYAML_Positional(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		content := `
+service:
+  positional: 2
+env:
+  positional: 1
+`
+		var actual Inputs
+		err := yaml.Unmarshal([]byte(content), &actual)
+		assert.NoError(t, err)
+		positional := actual.PositionalInputs()
+		assert.Len(t, positional, 2)
+		assert.Equal(t, "env", positional[0].Name)
+		assert.Equal(t, "service", positional[1].Name)
+	})
+	t.Run("duplicate position", func(t *testing.T) {
+		content := `
+service:
+  positional: 1
+env:
+  positional: 1
+`
+		err := yaml.Unmarshal([]byte(content), &Inputs{})
+		assert.ErrorContains(t, err, "inputs service and env share position 1")
+	})
+	t.Run("negative position", func(t *testing.T) {
+		content := `
+env:
+  positional: -1
+`
+		err := yaml.Unmarshal([]byte(content), &Inputs{})
+		assert.ErrorContains(t, err, "input position must be greater than zero")
+	})
+}
+
+func TestInputsUnmarshalYAML_Short(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		content := `
+env:
+  short: e
+`
+		var actual Inputs
+		err := yaml.Unmarshal([]byte(content), &actual)
+		assert.NoError(t, err)
+		assert.Equal(t, "e", actual.Inputs()[0].Short)
+	})
+	t.Run("invalid short name", func(t *testing.T) {
+		content := `
+env:
+  short: en
+`
+		err := yaml.Unmarshal([]byte(content), &Inputs{})
+		assert.ErrorContains(t, err, "input short name must be a single letter")
+	})
+	t.Run("duplicate short name", func(t *testing.T) {
+		content := `
+env:
+  short: e
+extra:
+  short: e
+`
+		err := yaml.Unmarshal([]byte(content), &Inputs{})
+		assert.ErrorContains(t, err, "inputs env and extra share short name e")
+	})
+}
+
+func TestInputsUnmarshalYAML_Required(t *testing.T) {
+	content := `
+inline: string
+no_default: {}
+with_default:
+  default: foobar
+explicit_required:
+  default: foobar
+  required: true
+explicit_optional:
+  required: false
+`
+	var actual Inputs
+	err := yaml.Unmarshal([]byte(content), &actual)
+	assert.NoError(t, err)
+	required := map[string]bool{}
+	for _, input := range actual.Inputs() {
+		required[input.Name] = input.Required()
+	}
+	expected := map[string]bool{
+		"inline":            true,
+		"no_default":        true,
+		"with_default":      false,
+		"explicit_required": true,
+		"explicit_optional": false,
+	}
+	assert.Equal(t, expected, required)
+}
+
+func TestInputsUnmarshalYAML_When(t *testing.T) {
+	content := `
+source: string
+tag:
+  when: eq .Input.source "registry"
+`
+	var actual Inputs
+	err := yaml.Unmarshal([]byte(content), &actual)
+	assert.NoError(t, err)
+	assert.Nil(t, actual.Inputs()[0].When)
+	assert.Equal(t, TemplateCondition(`eq .Input.source "registry"`), actual.Inputs()[1].When)
+}
+
+func TestInputsUnmarshalYAML_Integer(t *testing.T) {
+	content := `
+port:
+  type: integer
+  min: 1024
+  max: 65535
+  step: 10
+  default: 8080
+ratio:
+  type: number
+  step: 0.05
+  precision: 2
+`
+	var actual Inputs
+	err := yaml.Unmarshal([]byte(content), &actual)
+	assert.NoError(t, err)
+	assert.Equal(t, &inputs.IntegerValue{Value: 8080, MinValue: 1024, MaxValue: 65535, Step: 10}, actual.Inputs()[0].Value)
+	precision := 2
+	assert.Equal(t, &inputs.NumberValue{Step: 0.05, Precision: &precision}, actual.Inputs()[1].Value)
+
+	err = yaml.Unmarshal([]byte("port:\n  type: integer\n  default: 1.5\n"), &actual)
+	assert.Error(t, err)
+	err = yaml.Unmarshal([]byte("ratio:\n  type: number\n  step: -1\n"), &actual)
+	assert.ErrorContains(t, err, "input step must be greater than zero")
+	err = yaml.Unmarshal([]byte("ratio:\n  type: number\n  precision: -1\n"), &actual)
+	assert.ErrorContains(t, err, "input precision must not be negative")
+}
+
+func TestInputsUnmarshalYAML_Path(t *testing.T) {
+	content := `
+config:
+  type: path
+  kind: file
+  must_exist: true
+  extensions: [yml, yaml]
+  base: configs
+  picker: true
+  default: app.yml
+`
+	var actual Inputs
+	err := yaml.Unmarshal([]byte(content), &actual)
+	assert.NoError(t, err)
+	expected := &inputs.PathValue{
+		Value:      "app.yml",
+		MustExist:  true,
+		Kind:       inputs.PathKindFile,
+		Extensions: []string{"yml", "yaml"},
+		Base:       "configs",
+		Picker:     true,
+	}
+	assert.Equal(t, expected, actual.Inputs()[0].Value)
+
+	err = yaml.Unmarshal([]byte("config:\n  type: path\n  kind: socket\n"), &actual)
+	assert.ErrorContains(t, err, "path input kind must be file or dir")
+}
+
+func TestInputsUnmarshalYAML_Dates(t *testing.T) {
+	content := `
+release:
+  type: date
+  min: today
+  default: 2999-01-02
+window:
+  type: datetime
+  format: 2006-01-02T15:04
+  default: 2024-05-01T09:30
+timeout:
+  type: duration
+  min: 1m
+  max: 1d
+  step: 5m
+  default: 1h30m
+`
+	var actual Inputs
+	err := yaml.Unmarshal([]byte(content), &actual)
+	assert.NoError(t, err)
+	values := actual.Inputs()
+	assert.Equal(t, &inputs.DateValue{Value: time.Date(2999, 1, 2, 0, 0, 0, 0, time.Local), MinValue: "today"}, values[0].Value)
+	assert.Equal(t, &inputs.DateValue{Value: time.Date(2024, 5, 1, 9, 30, 0, 0, time.Local), Format: "2006-01-02T15:04", WithTime: true}, values[1].Value)
+	assert.Equal(t, &inputs.DurationValue{Value: 90 * time.Minute, MinValue: "1m", MaxValue: "1d", Step: "5m"}, values[2].Value)
+	assert.True(t, values[0].Optional)
+
+	err = yaml.Unmarshal([]byte("release:\n  type: date\n  default: soon\n"), &actual)
+	assert.ErrorContains(t, err, `invalid input default "soon"`)
+	err = yaml.Unmarshal([]byte("release:\n  type: date\n  max: today\n  default: tomorrow\n"), &actual)
+	assert.ErrorContains(t, err, "must not be after")
+	err = yaml.Unmarshal([]byte("timeout:\n  type: duration\n  step: fast\n"), &actual)
+	assert.ErrorContains(t, err, `invalid input step "fast"`)
+	err = yaml.Unmarshal([]byte("release:\n  type: date\n  min: tomorrow\n  max: today\n"), &actual)
+	assert.ErrorContains(t, err, "invalid input min")
+	err = yaml.Unmarshal([]byte("timeout:\n  type: duration\n  min: 1h\n  max: 30m\n"), &actual)
+	assert.ErrorContains(t, err, "invalid input min")
+}
+
+func TestInputsUnmarshalYAML_Multiline(t *testing.T) {
+	content := `
+message:
+  multiline: true
+  default: |
+    Fix bug
+
+    Details
+`
+	var actual Inputs
+	err := yaml.Unmarshal([]byte(content), &actual)
+	assert.NoError(t, err)
+	assert.Equal(t, &inputs.StringValue{Value: "Fix bug\n\nDetails\n", Multiline: true}, actual.Inputs()[0].Value)
+	assert.True(t, actual.Inputs()[0].Multiline())
+}
+
+func TestInputsUnmarshalYAML_Env(t *testing.T) {
+	var actual Inputs
+	err := yaml.Unmarshal([]byte("region:\n  env: AWS_REGION\ntag: string\n"), &actual)
+	assert.NoError(t, err)
+	assert.Equal(t, "AWS_REGION", actual.Inputs()[0].Env)
+	assert.Equal(t, "", actual.Inputs()[1].Env)
+
+	err = yaml.Unmarshal([]byte("region:\n  env: aws-region\n"), &actual)
+	assert.ErrorContains(t, err, `line 2: input env "aws-region" is not a valid environment variable name`)
+	err = yaml.Unmarshal([]byte("region:\n  env: REGION\nzone:\n  env: REGION\n"), &actual)
+	assert.ErrorContains(t, err, "inputs region and zone share env REGION")
+}
+
+func TestInputsUnmarshalYAML_Validate(t *testing.T) {
+	content := `
+name:
+  validate:
+    - lt (len .Value) 50
+    - template: '{{ ne .Value "admin" }}'
+      message: is reserved
+    - command: grep -qx "$ILC_VALUE" users.txt
+      timeout: 2s
+      message: must be a user
+`
+	var actual Inputs
+	err := yaml.Unmarshal([]byte(content), &actual)
+	assert.NoError(t, err)
+	expected := []inputs.Rule{
+		TemplateRule{Template: "lt (len .Value) 50"},
+		TemplateRule{Template: `{{ ne .Value "admin" }}`, Message: "is reserved"},
+		CommandRule{Command: `grep -qx "$ILC_VALUE" users.txt`, Timeout: 2 * time.Second, Message: "must be a user"},
+	}
+	assert.Equal(t, expected, actual.Inputs()[0].Rules)
+
+	err = yaml.Unmarshal([]byte("name:\n  validate:\n    - message: oops\n"), &actual)
+	assert.ErrorContains(t, err, "line 3: validate rule must have a template or a command")
+	err = yaml.Unmarshal([]byte("name:\n  validate:\n    - template: a\n      command: b\n"), &actual)
+	assert.ErrorContains(t, err, "line 3: validate rule cannot have both a template and a command")
+}
+
+func TestInputsUnmarshalYAML_Transform(t *testing.T) {
+	content := `
+name:
+  transform:
+    - trim
+    - slugify
+    - replace:
+        pattern: ^(feature|fix)-
+        with: $1/
+    - template: '{{ .Value }}-{{ .Input.env }}'
+`
+	var actual Inputs
+	err := yaml.Unmarshal([]byte(content), &actual)
+	assert.NoError(t, err)
+	expected := []inputs.Transform{
+		StringTransform("trim"),
+		StringTransform("slugify"),
+		ReplaceTransform{Pattern: "^(feature|fix)-", With: "$1/"},
+		TemplateTransform("{{ .Value }}-{{ .Input.env }}"),
+	}
+	assert.Equal(t, expected, actual.Inputs()[0].Transforms)
+
+	err = yaml.Unmarshal([]byte("name:\n  transform: [reverse]\n"), &actual)
+	assert.ErrorContains(t, err, `line 2: unknown transform "reverse"`)
+	err = yaml.Unmarshal([]byte("name:\n  transform:\n    - replace: {pattern: \"(\"}\n"), &actual)
+	assert.ErrorContains(t, err, "line 3: invalid replace pattern")
+	err = yaml.Unmarshal([]byte("name:\n  transform:\n    - template: a\n      replace: {pattern: b}\n"), &actual)
+	assert.ErrorContains(t, err, "line 3: transform cannot have both replace and a template")
+	err = yaml.Unmarshal([]byte("name:\n  transform:\n    - {}\n"), &actual)
+	assert.ErrorContains(t, err, "line 3: transform must be a name, replace or a template")
+	err = yaml.Unmarshal([]byte("count:\n  type: integer\n  transform: [trim]\n"), &actual)
+	assert.ErrorContains(t, err, "line 2: only string and list inputs can be transformed")
+}
+
+func TestInputsUnmarshalYAML_StringFormats(t *testing.T) {
+	var actual Inputs
+	err := yaml.Unmarshal([]byte("email:\n  format: email\n  min_length: 5\n  max_length: 64\n"), &actual)
+	assert.NoError(t, err)
+	assert.Equal(t, &inputs.StringValue{Format: "email", MinLength: 5, MaxLength: 64}, actual.Inputs()[0].Value)
+
+	err = yaml.Unmarshal([]byte("phone:\n  format: phone\n"), &actual)
+	assert.ErrorContains(t, err, `line 2: unknown string input format "phone"`)
+	err = yaml.Unmarshal([]byte("name:\n  min_length: 5\n  max_length: 2\n"), &actual)
+	assert.ErrorContains(t, err, "line 2: string input min_length 5 and max_length 2 are out of range")
+}
+
+func TestInputsUnmarshalYAML_Remember(t *testing.T) {
+	var actual Inputs
+	err := yaml.Unmarshal([]byte("cluster:\n  remember: true\napp:\n  remember: false\nregion: string\n"), &actual)
+	assert.NoError(t, err)
+	yes, no := true, false
+	assert.Equal(t, &yes, actual.Inputs()[0].Remember)
+	assert.Equal(t, &no, actual.Inputs()[1].Remember)
+	assert.Nil(t, actual.Inputs()[2].Remember)
+}
+
+func TestInputsUnmarshalYAML_Suggestions(t *testing.T) {
+	content := `
+branch:
+  suggestions: [main, develop]
+  suggestions_from:
+    command: git branch --format='%(refname:short)'
+    timeout: 2s
+`
+	var actual Inputs
+	err := yaml.Unmarshal([]byte(content), &actual)
+	assert.NoError(t, err)
+	branch := actual.Inputs()[0]
+	assert.Equal(t, []string{"main", "develop"}, branch.Suggestions)
+	assert.Equal(t, CommandOptions{Command: "git branch --format='%(refname:short)'", Timeout: 2 * time.Second}, branch.SuggestionsFrom)
+	assert.False(t, branch.Selectable())
+
+	err = yaml.Unmarshal([]byte("count:\n  type: integer\n  suggestions: [1, 2]\n"), &actual)
+	assert.ErrorContains(t, err, "line 2: only string inputs can have suggestions")
+	err = yaml.Unmarshal([]byte("env:\n  options: [dev, prod]\n  suggestions: [dev]\n"), &actual)
+	assert.ErrorContains(t, err, "line 2: input cannot have both options and suggestions")
+	err = yaml.Unmarshal([]byte("env:\n  suggestions_from:\n    timeout: 1s\n"), &actual)
+	assert.ErrorContains(t, err, "line 3: suggestions_from must have a command")
+}
+
+func TestInputsUnmarshalYAML_AllowOther(t *testing.T) {
+	var actual Inputs
+	err := yaml.Unmarshal([]byte("target:\n  options: [main, next]\n  allow_other: true\n"), &actual)
+	assert.NoError(t, err)
+	assert.True(t, actual.Inputs()[0].AllowOther)
+
+	err = yaml.Unmarshal([]byte("target:\n  allow_other: true\n"), &actual)
+	assert.ErrorContains(t, err, "line 2: input must have options to allow other values")
+	err = yaml.Unmarshal([]byte("services:\n  type: list\n  options: [api, web]\n  allow_other: true\n"), &actual)
+	assert.ErrorContains(t, err, "line 2: only inputs with a single option chosen can allow other values")
+}
+
+func TestInputsUnmarshalYAML_OptionEntries(t *testing.T) {
+	content := `
+env:
+  options:
+    - value: dev
+      label: Development
+      group: Pre-release
+      description: Local cluster
+    - staging
+    - label: Production
+      group: Release
+      disabled: Frozen until Monday
+month:
+  options:
+    January: 1
+    February:
+      value: 2
+      description: Short month
+    March:
+      disabled: Not yet
+confirm:
+  type: boolean
+  options:
+    true:
+      value: Yes
+      description: Go ahead
+    false: No
+`
+	var actual Inputs
+	err := yaml.Unmarshal([]byte(content), &actual)
+	assert.NoError(t, err)
+	assert.Equal(t, inputs.InputOptions{
+		{Label: "Development", Value: "dev", Description: "Local cluster", Group: "Pre-release"},
+		{Label: "staging", Value: "staging"},
+		{Label: "Production", Value: "Production", Group: "Release", Disabled: "Frozen until Monday"},
+	}, actual.Inputs()[0].Options)
+	assert.Equal(t, inputs.InputOptions{
+		{Label: "January", Value: "1"},
+		{Label: "February", Value: "2", Description: "Short month"},
+		{Label: "March", Value: "March", Disabled: "Not yet"},
+	}, actual.Inputs()[1].Options)
+	assert.Equal(t, inputs.InputOptions{
+		{Label: "Yes", Value: "true", Description: "Go ahead"},
+		{Label: "No", Value: "false"},
+	}, actual.Inputs()[2].Options)
+
+	err = yaml.Unmarshal([]byte("env:\n  options:\n    - description: Nameless\n"), &actual)
+	assert.ErrorContains(t, err, "line 3: option must have a label or a value")
+	err = yaml.Unmarshal([]byte("env:\n  options:\n    - [dev]\n"), &actual)
+	assert.ErrorContains(t, err, "line 3: option must be a value or a mapping")
+	err = yaml.Unmarshal([]byte("env:\n  options:\n    - value: prod\n      disabled: true\n"), &actual)
+	assert.ErrorContains(t, err, "line 4: disabled must be the reason the option is disabled")
+}
+
+func TestInputsUnmarshalYAML_Secret(t *testing.T) {
+	content := `
+token:
+  secret: true
+user: string
+`
+	var actual Inputs
+	err := yaml.Unmarshal([]byte(content), &actual)
+	assert.NoError(t, err)
+	assert.True(t, actual.Inputs()[0].Secret)
+	assert.False(t, actual.Inputs()[1].Secret)
+}
+
+func TestInputsUnmarshalYAML_List(t *testing.T) {
+	content := `
+services:
+  type: list
+  options: [api, web, db]
+  default: [api]
+  min_items: 1
+  max_items: 2
+  separator: " "
+files: list
+`
+	var actual Inputs
+	err := yaml.Unmarshal([]byte(content), &actual)
+	assert.NoError(t, err)
+	assert.Equal(t, &inputs.ListValue{Values: []string{"api"}, MinItems: 1, MaxItems: 2, Separator: " "}, actual.Inputs()[0].Value)
+	assert.Len(t, actual.Inputs()[0].Options, 3)
+	assert.True(t, actual.Inputs()[0].Optional)
+	assert.Equal(t, &inputs.ListValue{}, actual.Inputs()[1].Value)
+
+	err = yaml.Unmarshal([]byte("services:\n  type: list\n  min_items: 3\n  max_items: 2\n"), &actual)
+	assert.ErrorContains(t, err, "list input min_items 3 and max_items 2 are out of range")
+}
+
+func TestInputsUnmarshalYAML_Templates(t *testing.T) {
+	content := `
+env:
+  options: [staging, production]
+service:
+  options:
+    "API ({{ .Input.env }})": "{{ .Input.env }}-api"
+    Web: web
+replicas:
+  type: number
+  default: "{{ if eq .Input.env \"production\" }}3{{ else }}1{{ end }}"
+name:
+  default: app
+`
+	var actual Inputs
+	err := yaml.Unmarshal([]byte(content), &actual)
+	assert.NoError(t, err)
+	inps := actual.Inputs()
+	assert.Nil(t, inps[0].OptionsFrom)
+	assert.Equal(t, TemplateOptions{{Label: "API ({{ .Input.env }})", Value: "{{ .Input.env }}-api"}, {Label: "Web", Value: "web"}}, inps[1].OptionsFrom)
+	assert.Empty(t, inps[1].Options)
+	assert.Equal(t, TemplateDefault(`{{ if eq .Input.env "production" }}3{{ else }}1{{ end }}`), inps[2].DefaultFrom)
+	assert.True(t, inps[2].Optional)
+	assert.Nil(t, inps[3].DefaultFrom)
+	assert.Equal(t, "app", inps[3].Value.String())
+
+	_, err = actual.ParseEnvAndArgs([]string{"-env", "production", "-service", "production-api"}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "3", inps[2].Value.String())
+}
+
+func TestInputsUnmarshalYAML_OptionsFrom(t *testing.T) {
+	t.Run("command", func(t *testing.T) {
+		content := `
+branch:
+  options_from: git branch --format='%(refname:short)'
+`
+		var actual Inputs
+		err := yaml.Unmarshal([]byte(content), &actual)
+		assert.NoError(t, err)
+		assert.Equal(t, CommandOptions{Command: "git branch --format='%(refname:short)'"}, actual.Inputs()[0].OptionsFrom)
+		assert.True(t, actual.Inputs()[0].Selectable())
+	})
+
+	t.Run("command with timeout", func(t *testing.T) {
+		content := `
+context:
+  options_from:
+    command: kubectl config get-contexts -o name
+    timeout: 5s
+`
+		var actual Inputs
+		err := yaml.Unmarshal([]byte(content), &actual)
+		assert.NoError(t, err)
+		assert.Equal(t, CommandOptions{Command: "kubectl config get-contexts -o name", Timeout: 5 * time.Second}, actual.Inputs()[0].OptionsFrom)
+	})
+
+	t.Run("without options_from", func(t *testing.T) {
+		var actual Inputs
+		err := yaml.Unmarshal([]byte("branch: string"), &actual)
+		assert.NoError(t, err)
+		assert.Nil(t, actual.Inputs()[0].OptionsFrom)
+	})
+
+	t.Run("missing command", func(t *testing.T) {
+		var actual Inputs
+		err := yaml.Unmarshal([]byte("branch:\n  options_from:\n    timeout: 5s\n"), &actual)
+		assert.ErrorContains(t, err, "options_from must have a command")
+	})
+
+	t.Run("with static options", func(t *testing.T) {
+		var actual Inputs
+		err := yaml.Unmarshal([]byte("branch:\n  options: [main]\n  options_from: git branch\n"), &actual)
+		assert.ErrorContains(t, err, "input cannot have both options and options_from")
+	})
+}
@@ -0,0 +1,305 @@
+package inputs
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+)
+
+const negatePrefix = "no-"
+
+func isBoolean(input *Input) bool {
+	_, isBool := input.Value.(*BooleanValue)
+	return isBool
+}
+
+// argParser parses GNU style options against the inputs of a FlagSet. Long
+// options may be given as `--name value`, `--name=value` or, for
+// compatibility, with a single dash. Short options may be combined when they
+// are booleans, ie. `-fv`, and boolean inputs can be negated with `--no-name`.
+// List inputs collect the values of each time their option is given.
+type argParser struct {
+	fs      *FlagSet
+	args    []string
+	bare    []string
+	flagged map[string]bool
+	lists   map[string][]string
+}
+
+func (p *argParser) lookup(name string) *Input {
+	for _, input := range p.fs.inputs {
+		if input.Name == name {
+			return input
+		}
+	}
+	return nil
+}
+
+func (p *argParser) lookupShort(short string) *Input {
+	for _, input := range p.fs.inputs {
+		if input.Short != "" && input.Short == short {
+			return input
+		}
+	}
+	return nil
+}
+
+func (p *argParser) set(input *Input, option, value string) error {
+	if _, isList := input.Value.(*ListValue); isList {
+		if p.lists == nil {
+			p.lists = make(map[string][]string)
+		}
+		p.lists[input.Name] = append(p.lists[input.Name], value)
+		p.flagged[input.Name] = true
+		return nil
+	}
+	if err := p.fs.set(input, value); err != nil {
+		return fmt.Errorf("invalid value %s for input %s: %w", input.DisplayArg(value), option, err)
+	}
+	p.flagged[input.Name] = true
+	return nil
+}
+
+// next consumes the following argument as the value of the option
+func (p *argParser) next(option string) (string, error) {
+	if len(p.args) == 0 {
+		return "", fmt.Errorf("input %s requires a value", option)
+	}
+	value := p.args[0]
+	p.args = p.args[1:]
+	return value, nil
+}
+
+func (p *argParser) parseLong(option, body string) error {
+	name, value, hasValue := strings.Cut(body, "=")
+	input := p.lookup(name)
+	if input == nil {
+		if negated := p.lookup(strings.TrimPrefix(name, negatePrefix)); negated != nil && name != negated.Name && isBoolean(negated) {
+			if hasValue {
+				return fmt.Errorf("input %s does not take a value", option)
+			}
+			return p.set(negated, option, "false")
+		}
+		if name == "h" || name == "help" {
+			return flag.ErrHelp
+		}
+		return fmt.Errorf("unknown input: %s", option)
+	}
+	if !hasValue {
+		if isBoolean(input) {
+			value = "true"
+		} else {
+			var err error
+			if value, err = p.next(option); err != nil {
+				return err
+			}
+		}
+	}
+	return p.set(input, option, value)
+}
+
+func (p *argParser) parseShort(option, body string) error {
+	for len(body) > 0 {
+		short := body[:1]
+		body = body[1:]
+		input := p.lookupShort(short)
+		if input == nil {
+			if short == "h" {
+				return flag.ErrHelp
+			}
+			return fmt.Errorf("unknown input: -%s", short)
+		}
+		if isBoolean(input) && !strings.HasPrefix(body, "=") {
+			if err := p.set(input, "-"+short, "true"); err != nil {
+				return err
+			}
+			continue
+		}
+		value := strings.TrimPrefix(body, "=")
+		if body == "" {
+			var err error
+			if value, err = p.next(option); err != nil {
+				return err
+			}
+		}
+		return p.set(input, "-"+short, value)
+	}
+	return nil
+}
+
+func (p *argParser) parse() error {
+	for len(p.args) > 0 {
+		arg := p.args[0]
+		p.args = p.args[1:]
+		switch {
+		case arg == "--":
+			p.bare = append(p.bare, p.args...)
+			p.args = nil
+		case strings.HasPrefix(arg, "--"):
+			if err := p.parseLong(arg, arg[2:]); err != nil {
+				return err
+			}
+		case strings.HasPrefix(arg, "-") && len(arg) > 1:
+			body := arg[1:]
+			name, _, _ := strings.Cut(body, "=")
+			// Single dash long options take precedence over short options
+			if p.lookup(name) != nil || p.lookup(strings.TrimPrefix(name, negatePrefix)) != nil || len(name) > 1 && p.lookupShort(name[:1]) == nil {
+				if err := p.parseLong(arg, body); err != nil {
+					return err
+				}
+			} else if err := p.parseShort(arg, body); err != nil {
+				return err
+			}
+		default:
+			p.bare = append(p.bare, arg)
+		}
+	}
+	for _, input := range p.fs.inputs {
+		if values, found := p.lists[input.Name]; found {
+			value := strings.Join(values, DefaultListSeparator)
+			if err := p.fs.set(input, value); err != nil {
+				return fmt.Errorf("invalid value %s for input %s: %w", input.DisplayArg(value), input.Option(), err)
+			}
+		}
+	}
+	return nil
+}
+
+// OptionArgs returns the number of arguments the option at the start of args
+// consumes, including its value, or zero when it is not an option of the
+// FlagSet.
+func (fs *FlagSet) OptionArgs(args []string) int {
+	if len(args) == 0 {
+		return 0
+	}
+	p := argParser{fs: fs}
+	arg := args[0]
+	body, isLong := strings.CutPrefix(arg, "--")
+	if !isLong {
+		if !strings.HasPrefix(arg, "-") || len(arg) < 2 {
+			return 0
+		}
+		body = arg[1:]
+	}
+	name, _, hasValue := strings.Cut(body, "=")
+	input := p.lookup(name)
+	if input == nil {
+		if negated := p.lookup(strings.TrimPrefix(name, negatePrefix)); negated != nil && isBoolean(negated) {
+			return 1
+		}
+	}
+	if input == nil && !isLong {
+		// Walk the short options until one takes a value
+		for i := range body {
+			short := p.lookupShort(body[i : i+1])
+			if short == nil {
+				return 0
+			}
+			if !isBoolean(short) || strings.HasPrefix(body[i+1:], "=") {
+				if i+1 < len(body) {
+					return 1
+				}
+				return min(2, len(args))
+			}
+		}
+		return 1
+	}
+	if input == nil {
+		return 0
+	}
+	if hasValue || isBoolean(input) {
+		return 1
+	}
+	return min(2, len(args))
+}
+
+// valueInput returns the input of the option at the start of arg that takes a
+// value, and the offset of the value when attached to the option, or the
+// length of arg when the value is the following argument.
+func (p *argParser) valueInput(arg string) (*Input, int) {
+	body, isLong := strings.CutPrefix(arg, "--")
+	if !isLong {
+		if !strings.HasPrefix(arg, "-") || len(arg) < 2 {
+			return nil, 0
+		}
+		body = arg[1:]
+	}
+	offset := len(arg) - len(body)
+	name, _, hasValue := strings.Cut(body, "=")
+	if input := p.lookup(name); input != nil {
+		if isBoolean(input) && !hasValue {
+			return nil, 0
+		}
+		if hasValue {
+			return input, offset + len(name) + 1
+		}
+		return input, len(arg)
+	}
+	if isLong {
+		return nil, 0
+	}
+	for i := range body {
+		short := p.lookupShort(body[i : i+1])
+		if short == nil {
+			return nil, 0
+		}
+		if rest := body[i+1:]; !isBoolean(short) || strings.HasPrefix(rest, "=") {
+			if strings.HasPrefix(rest, "=") {
+				return short, offset + i + 2
+			}
+			return short, offset + i + 1
+		}
+	}
+	return nil, 0
+}
+
+// RedactArgs returns a copy of args with the values given to secret inputs,
+// as options or positional arguments, replaced by RedactedValue. Arguments
+// after `--` are kept as is.
+func (fs *FlagSet) RedactArgs(args []string) []string {
+	p := argParser{fs: fs}
+	redacted := make([]string, 0, len(args))
+	flagged := make(map[string]bool)
+	var bare []int
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			redacted = append(redacted, args[i:]...)
+			break
+		}
+		n := fs.OptionArgs(args[i:])
+		if n == 0 {
+			bare = append(bare, len(redacted))
+			redacted = append(redacted, arg)
+			continue
+		}
+		input, offset := p.valueInput(arg)
+		if input != nil {
+			flagged[input.Name] = true
+		}
+		if input != nil && input.Secret && offset < len(arg) {
+			arg = arg[:offset] + RedactedValue
+		}
+		redacted = append(redacted, arg)
+		if n > 1 {
+			i++
+			if input != nil && input.Secret {
+				redacted = append(redacted, RedactedValue)
+			} else {
+				redacted = append(redacted, args[i])
+			}
+		}
+	}
+	var positional []*Input
+	for _, input := range fs.PositionalInputs() {
+		if !flagged[input.Name] {
+			positional = append(positional, input)
+		}
+	}
+	for i, index := range bare {
+		if i < len(positional) && positional[i].Secret {
+			redacted[index] = RedactedValue
+		}
+	}
+	return redacted
+}
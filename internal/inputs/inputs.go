@@ -2,19 +2,34 @@ package inputs
 
 import (
 	"errors"
-	"flag"
 	"fmt"
+	"reflect"
 	"slices"
+	"strconv"
 	"strings"
 )
 
 var (
-	ErrAborted = errors.New("aborted")
+	ErrAborted        = errors.New("aborted")
+	ErrNoOptions      = errors.New("no options available")
+	ErrNotAnOption    = errors.New("not one of the options")
+	ErrDisabledOption = errors.New("disabled option")
 )
 
+// RedactedValue is shown in place of the values of secret inputs
+const RedactedValue = "******"
+
+// maxSuggestions is the most options suggested for a value that doesn't match
+const maxSuggestions = 3
+
 type InputOption struct {
-	Label string `yaml:"label"`
-	Value string `yaml:"value"`
+	Label       string `yaml:"label"`
+	Value       string `yaml:"value"`
+	Description string `yaml:"description"`
+	// Group heads the options that follow in a prompt
+	Group string `yaml:"group"`
+	// Disabled is the reason the option can't be chosen, if any
+	Disabled string `yaml:"disabled"`
 }
 
 func (o InputOption) String() string {
@@ -32,54 +47,311 @@ func (options InputOptions) Contains(value string) bool {
 	return false
 }
 
+// Lookup returns the option matching s, by value before label, falling back to
+// a case insensitive match when only one option matches that way.
+func (options InputOptions) Lookup(s string) (InputOption, bool) {
+	for _, option := range options {
+		if option.Value == s {
+			return option, true
+		}
+	}
+	for _, option := range options {
+		if option.Label == s {
+			return option, true
+		}
+	}
+	var found []InputOption
+	for _, option := range options {
+		if strings.EqualFold(option.Value, s) || strings.EqualFold(option.Label, s) {
+			found = append(found, option)
+		}
+	}
+	if len(found) == 1 {
+		return found[0], true
+	}
+	return InputOption{}, false
+}
+
+// Resolve returns the value of the option matching s, unless it's disabled.
+// Otherwise the error suggests the closest options, or lists them all when none
+// are close.
+func (options InputOptions) Resolve(s string) (string, error) {
+	if option, found := options.Lookup(s); found && option.Disabled != "" {
+		return "", fmt.Errorf("%w %s: %s", ErrDisabledOption, option.Label, option.Disabled)
+	} else if found {
+		return option.Value, nil
+	}
+	if suggestions := options.Suggest(s); len(suggestions) > 0 {
+		for i, suggestion := range suggestions {
+			suggestions[i] = strconv.Quote(suggestion)
+		}
+		return "", fmt.Errorf("%w, did you mean %s?", ErrNotAnOption, strings.Join(suggestions, " or "))
+	}
+	var values []string
+	for _, option := range options {
+		if option.Disabled == "" {
+			values = append(values, option.Value)
+		}
+	}
+	return "", fmt.Errorf("%w: %s", ErrNotAnOption, strings.Join(values, ", "))
+}
+
+// Suggest returns the values or labels of the options close to or starting
+// with s, ordered by their edit distance. Disabled options aren't suggested.
+func (options InputOptions) Suggest(s string) []string {
+	type candidate struct {
+		text     string
+		distance int
+	}
+	var candidates []candidate
+	lower := strings.ToLower(s)
+	for _, option := range options {
+		if option.Disabled != "" {
+			continue
+		}
+		best := candidate{distance: -1}
+		isClose := false
+		for _, text := range []string{option.Value, option.Label} {
+			d := editDistance(lower, strings.ToLower(text))
+			if best.distance < 0 || d < best.distance {
+				best = candidate{text: text, distance: d}
+			}
+			// Allow about one typo for every three characters, or a prefix
+			isClose = isClose || d <= len([]rune(text))/3 || s != "" && strings.HasPrefix(strings.ToLower(text), lower)
+		}
+		if isClose {
+			candidates = append(candidates, best)
+		}
+	}
+	slices.SortStableFunc(candidates, func(a, b candidate) int {
+		return a.distance - b.distance
+	})
+	var suggestions []string
+	for _, c := range candidates[:min(len(candidates), maxSuggestions)] {
+		suggestions = append(suggestions, c.text)
+	}
+	return suggestions
+}
+
+// editDistance returns the Levenshtein distance between a and b
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
+
+// Condition decides whether an input applies, given the values of the
+// applicable inputs declared before it.
+type Condition interface {
+	Evaluate(values map[string]any) (bool, error)
+}
+
+// OptionsSource generates the options of an input, given the values of the
+// applicable inputs declared before it.
+type OptionsSource interface {
+	Options(values map[string]any) (InputOptions, error)
+}
+
+// DefaultSource renders the default value of an input, given the values of
+// the applicable inputs declared before it.
+type DefaultSource interface {
+	Default(values map[string]any) (string, error)
+}
+
+// Rule checks the value of an input, given the values of the applicable inputs
+// declared before it, returning why the value is invalid.
+type Rule interface {
+	Check(value Value, values map[string]any) error
+}
+
+// LiveRule is a Rule quick enough to check as the value is typed.
+type LiveRule interface {
+	Rule
+	CheckLive(value Value, values map[string]any) error
+}
+
+// Transform normalises the value of an input, given the values of the
+// applicable inputs declared before it.
+type Transform interface {
+	Transform(s string, values map[string]any) (string, error)
+}
+
 type Input struct {
-	Name        string       `yaml:"-"`
-	Description string       `yaml:"description"`
-	Options     InputOptions `yaml:"options,flow"`
-	Value       Value        `yaml:"value"`
+	Name            string        `yaml:"-"`
+	Description     string        `yaml:"description"`
+	Options         InputOptions  `yaml:"options,flow"`
+	AllowOther      bool          `yaml:"allow_other"`
+	Suggestions     []string      `yaml:"suggestions,flow"`
+	Short           string        `yaml:"short"`
+	Position        int           `yaml:"positional"`
+	Optional        bool          `yaml:"optional"`
+	Secret          bool          `yaml:"secret"`
+	Env             string        `yaml:"env"`
+	Remember        *bool         `yaml:"remember"`
+	When            Condition     `yaml:"-"`
+	OptionsFrom     OptionsSource `yaml:"-"`
+	SuggestionsFrom OptionsSource `yaml:"-"`
+	DefaultFrom     DefaultSource `yaml:"-"`
+	Rules           []Rule        `yaml:"-"`
+	Transforms      []Transform   `yaml:"-"`
+	Value           Value         `yaml:"value"`
+	// Recorded are values of the input already transformed, ie. recorded in
+	// history, which are used as is when given again
+	Recorded []string `yaml:"-"`
+	// raw is a copy of the value before its transforms, once they've applied
+	raw Value
 }
 
 func (input Input) EnvName() string {
 	return strings.ReplaceAll(strings.ToUpper(input.Name), "-", "_")
 }
 
+// RawValue returns the value of the input as it was given, before its
+// transforms applied.
+func (input Input) RawValue() Value {
+	if input.raw != nil {
+		return input.raw
+	}
+	return input.Value
+}
+
+// recorded reports whether the value of the input is one already transformed,
+// which isn't checked or transformed again.
+func (input Input) recorded() bool {
+	return input.raw == nil && slices.Contains(input.Recorded, input.Value.String())
+}
+
 func (input Input) Selectable() bool {
-	return len(input.Options) > 0
+	return len(input.Options) > 0 || input.OptionsFrom != nil
+}
+
+// Required reports whether the input must be given a value rather than
+// falling back to its default.
+func (input Input) Required() bool {
+	return !input.Optional
 }
 
-type trackingValue struct {
-	val      Value
-	provided *bool
+// DisplayValue returns the value of the input to show, which is redacted for
+// secret inputs and shortened to its first line for multiline ones.
+func (input Input) DisplayValue() string {
+	if input.Secret {
+		return RedactedValue
+	}
+	value := input.Value.String()
+	if first, _, found := strings.Cut(value, "\n"); found && input.Multiline() {
+		return first + " …"
+	}
+	return value
 }
 
-func (t trackingValue) String() string {
-	return t.val.String()
+// DisplayArg returns how a value given for the input is quoted in messages,
+// which is redacted for secret inputs.
+func (input Input) DisplayArg(value string) string {
+	if input.Secret {
+		return RedactedValue
+	}
+	return strconv.Quote(value)
 }
 
-func (t trackingValue) Set(s string) error {
-	*t.provided = true
-	return t.val.Set(s)
+// Multiline reports whether the input's value spans several lines.
+func (input Input) Multiline() bool {
+	v, ok := input.Value.(*StringValue)
+	return ok && v.Multiline && !input.Selectable()
+}
+
+func (input Input) Positional() bool {
+	return input.Position > 0
+}
+
+// Option returns the canonical long option of the input, ie. `--name`.
+func (input Input) Option() string {
+	return "--" + input.Name
+}
+
+// OptionNames returns the option spellings accepted for the input, with boolean
+// inputs including their negated form.
+func (input Input) OptionNames() []string {
+	var names []string
+	if input.Short != "" {
+		names = append(names, "-"+input.Short)
+	}
+	if isBoolean(&input) {
+		names = append(names, "--["+negatePrefix+"]"+input.Name)
+	} else {
+		names = append(names, input.Option())
+	}
+	return names
 }
 
 type FlagSet struct {
 	name           string
 	envPrefix      string
+	envPrefixes    map[string]string
 	inputs         []*Input
 	provided       map[string]*bool
+	pending        map[string]string
+	args           []string
 	Prompter       Prompter
+	AcceptDefaults bool
 }
 
 func NewFlagSet(name string, envPrefix string) *FlagSet {
 	return &FlagSet{
-		name:      name,
-		envPrefix: envPrefix,
-		provided:  make(map[string]*bool),
+		name:        name,
+		envPrefix:   envPrefix,
+		envPrefixes: make(map[string]string),
+		provided:    make(map[string]*bool),
+		pending:     make(map[string]string),
+	}
+}
+
+// WithEnvPrefix returns a FlagSet of the same inputs, reading and exporting
+// those merged without their own prefix with the prefix instead.
+func (fs *FlagSet) WithEnvPrefix(prefix string) *FlagSet {
+	prefixed := NewFlagSet(fs.name, prefix)
+	for _, input := range fs.inputs {
+		prefixed.Var(input)
+		if p, found := fs.envPrefixes[input.Name]; found && p != prefix {
+			prefixed.envPrefixes[input.Name] = p
+		}
+	}
+	return prefixed
+}
+
+// EnvVar returns the name of the environment variable the input is read from
+// and exported as, which is its own or its name with the prefix.
+func (fs *FlagSet) EnvVar(input *Input) string {
+	if input.Env != "" {
+		return input.Env
+	}
+	return fs.envPrefixOf(input) + input.EnvName()
+}
+
+func (fs *FlagSet) envPrefixOf(input *Input) string {
+	if prefix, found := fs.envPrefixes[input.Name]; found {
+		return prefix
 	}
+	return fs.envPrefix
 }
 
 func (fs *FlagSet) getPrompter() Prompter {
 	if fs.Prompter == nil {
-		return TuiPrompter{}
+		return TuiPrompter{flagSet: fs}
 	}
 	return fs.Prompter
 }
@@ -90,41 +362,213 @@ func (fs *FlagSet) Var(input *Input) {
 	fs.provided[input.Name] = &provided
 }
 
+// set gives the input a value like assign, reading the values of multiline
+// inputs from the file they refer to.
+func (fs *FlagSet) set(input *Input, value string) error {
+	if input.Multiline() {
+		var err error
+		if value, err = readFileRef(value); err != nil {
+			return err
+		}
+	}
+	return fs.assign(input, value)
+}
+
+// assign gives the input a value, matched against its options. Values of
+// inputs with generated options are held until the options are generated.
+func (fs *FlagSet) assign(input *Input, value string) error {
+	*fs.provided[input.Name] = true
+	if input.OptionsFrom != nil {
+		fs.pending[input.Name] = value
+		return nil
+	}
+	return setValue(input, input.Options, value)
+}
+
+// setValue sets the value of the input, matching it, or each of its items for
+// lists, against the options when there are any. Errors for secret inputs
+// don't describe the value, as they may repeat it.
+func setValue(input *Input, options InputOptions, value string) error {
+	err := setOptionValue(input, options, value)
+	if err != nil && input.Secret {
+		return ErrInvalidValue
+	} else if err == nil {
+		input.raw = nil
+	}
+	return err
+}
+
+func setOptionValue(input *Input, options InputOptions, value string) error {
+	if len(options) == 0 || slices.Contains(input.Recorded, value) {
+		return input.Value.Set(value)
+	}
+	if list, ok := input.Value.(*ListValue); ok {
+		items := splitList(value, DefaultListSeparator)
+		for i, item := range items {
+			resolved, err := options.Resolve(item)
+			if err != nil {
+				return fmt.Errorf("%s: %w", item, err)
+			}
+			items[i] = resolved
+		}
+		return list.SetItems(items)
+	}
+	resolved, err := options.Resolve(value)
+	if errors.Is(err, ErrNotAnOption) && input.AllowOther {
+		return input.Value.Set(value)
+	} else if err != nil {
+		return err
+	}
+	return input.Value.Set(resolved)
+}
+
+// Check reports whether the value is valid for the input without changing the
+// input's value. Values of inputs with generated options aren't matched against
+// them, as generating them can run commands.
+func (input Input) Check(value string) error {
+	input.Value = cloneValue(input.Value)
+	return setValue(&input, input.Options, value)
+}
+
+// cloneValue returns a copy of the value, so setting it leaves the original
+// as it was.
+func cloneValue(v Value) Value {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return v
+	}
+	clone := reflect.New(rv.Elem().Type())
+	clone.Elem().Set(rv.Elem())
+	return clone.Interface().(Value)
+}
+
+// restoreValue sets the value back to a copy made with cloneValue.
+func restoreValue(v, clone Value) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Pointer && !rv.IsNil() {
+		rv.Elem().Set(reflect.ValueOf(clone).Elem())
+	}
+}
+
 func (fs *FlagSet) Inputs() []*Input {
 	return fs.inputs
 }
 
+// PositionalInputs returns the inputs that can be given as bare arguments,
+// ordered by their position.
+func (fs *FlagSet) PositionalInputs() []*Input {
+	var positional []*Input
+	for _, input := range fs.inputs {
+		if input.Positional() {
+			positional = append(positional, input)
+		}
+	}
+	slices.SortStableFunc(positional, func(a, b *Input) int {
+		return a.Position - b.Position
+	})
+	return positional
+}
+
+// Args returns the bare arguments remaining after parsing that were not
+// consumed by positional inputs.
+func (fs *FlagSet) Args() []string {
+	return fs.args
+}
+
 func (fs *FlagSet) ParseEnvAndArgs(args []string, envs map[string]string) ([]*Input, error) {
+	return fs.ParseValuesEnvAndArgs(nil, args, envs)
+}
+
+// ParseValuesEnvAndArgs is ParseEnvAndArgs with values given first, ie. read
+// from a values file, which environment variables and arguments override.
+// Values of inputs the FlagSet doesn't have are ignored.
+func (fs *FlagSet) ParseValuesEnvAndArgs(values map[string]string, args []string, envs map[string]string) ([]*Input, error) {
 	// Reset provided status
 	for _, provided := range fs.provided {
 		*provided = false
 	}
+	clear(fs.pending)
 
-	stdFs := flag.NewFlagSet("", flag.ContinueOnError)
-	stdFs.Usage = func() {} // Silent usage printout
-
-	// Register variables with tracking wrapper
+	// 1. Process the given values
 	for _, input := range fs.inputs {
-		provided := fs.provided[input.Name]
-		stdFs.Var(trackingValue{val: input.Value, provided: provided}, input.Name, input.Description)
+		if value, found := values[input.Name]; found {
+			if err := fs.assign(input, value); err != nil {
+				return nil, fmt.Errorf("invalid value %s for input %s: %w", input.DisplayArg(value), input.Name, err)
+			}
+		}
 	}
 
-	// 1. Process environment variables
+	// 2. Process environment variables
 	for _, input := range fs.inputs {
-		envName := fs.envPrefix + input.EnvName()
+		envName := fs.EnvVar(input)
 		if envVal, found := envs[envName]; found {
-			if err := stdFs.Set(input.Name, envVal); err != nil {
+			if list, ok := input.Value.(*ListValue); ok {
+				envVal = strings.Join(list.SplitEnv(envVal), DefaultListSeparator)
+			}
+			if err := fs.set(input, envVal); err != nil {
 				return nil, fmt.Errorf("invalid environment variable %s: %w", envName, err)
 			}
 		}
 	}
 
-	// 2. Parse command-line flags
-	if err := stdFs.Parse(args); err != nil {
+	// 3. Parse command-line options, collecting bare arguments in between
+	p := argParser{fs: fs, args: args, flagged: make(map[string]bool)}
+	if err := p.parse(); err != nil {
 		return nil, err
 	}
 
-	// 3. Collect missing inputs
+	// 4. Fill positional inputs not given as options from the bare arguments
+	bare := p.bare
+	for _, input := range fs.PositionalInputs() {
+		if len(bare) == 0 {
+			break
+		}
+		if p.flagged[input.Name] {
+			continue
+		}
+		if err := fs.set(input, bare[0]); err != nil {
+			return nil, fmt.Errorf("invalid value %s for input %s: %w", input.DisplayArg(bare[0]), input.Name, err)
+		}
+		bare = bare[1:]
+	}
+	fs.args = bare
+
+	// 5. Render the defaults of inputs not given and transform them, and match
+	// the values given to inputs with generated options, check their rules and
+	// transform them, in order as each can depend on the last
+	for _, input := range fs.inputs {
+		if !*fs.provided[input.Name] {
+			if err := fs.ApplyDefault(input); err != nil {
+				return nil, err
+			}
+			if applies, _ := fs.Applies(input); applies {
+				if err := fs.Transform(input); err != nil {
+					return nil, fmt.Errorf("failed to transform input %s: %w", input.Name, err)
+				}
+			}
+			continue
+		}
+		if value, found := fs.pending[input.Name]; found {
+			options, err := fs.GenerateOptions(input)
+			if err != nil {
+				return nil, err
+			}
+			input.Options = options
+			if err := setValue(input, options, value); err != nil {
+				return nil, fmt.Errorf("invalid value %s for input %s: %w", input.DisplayArg(value), input.Name, err)
+			}
+		}
+		if applies, _ := fs.Applies(input); applies && !input.recorded() {
+			if err := fs.CheckRules(input); err != nil {
+				return nil, fmt.Errorf("invalid value %s for input %s: %w", input.DisplayArg(input.Value.String()), input.Name, err)
+			}
+			if err := fs.Transform(input); err != nil {
+				return nil, fmt.Errorf("failed to transform input %s: %w", input.Name, err)
+			}
+		}
+	}
+
+	// 6. Collect missing inputs
 	var missing []*Input
 	for _, input := range fs.inputs {
 		if !*fs.provided[input.Name] {
@@ -140,8 +584,16 @@ func (fs *FlagSet) Parse(args []string, envs map[string]string, nonInteractive b
 	if err != nil {
 		return err
 	}
+	if nonInteractive || fs.AcceptDefaults {
+		missing = Required(missing)
+	}
+	if nonInteractive {
+		if missing, err = fs.Applicable(missing); err != nil {
+			return err
+		}
+	}
 
-	// 4. Prompt for missing inputs if any
+	// 7. Prompt for missing inputs if any
 	if len(missing) > 0 {
 		if nonInteractive {
 			var missingNames []string
@@ -160,6 +612,255 @@ func (fs *FlagSet) Parse(args []string, envs map[string]string, nonInteractive b
 	return nil
 }
 
+// Active returns the inputs whose conditions apply, evaluated in declaration
+// order against the values of the active inputs before them. Inputs whose
+// condition fails to evaluate are left out and the first error is returned.
+func (fs *FlagSet) Active() ([]*Input, error) {
+	var active []*Input
+	var firstErr error
+	values := make(map[string]any, len(fs.inputs))
+	for _, input := range fs.inputs {
+		if input.When != nil {
+			applies, err := input.When.Evaluate(values)
+			if err != nil && firstErr == nil {
+				firstErr = fmt.Errorf("invalid condition for input %s: %w", input.Name, err)
+			}
+			if err != nil || !applies {
+				continue
+			}
+		}
+		active = append(active, input)
+		values[input.Name] = input.Value.Get()
+	}
+	return active, firstErr
+}
+
+// PriorValues returns the values of the active inputs declared before the
+// input, which conditions, generated options and defaults are evaluated
+// against.
+func (fs *FlagSet) PriorValues(input *Input) map[string]any {
+	values := make(map[string]any)
+	active, _ := fs.Active()
+	for _, prior := range fs.inputs {
+		if prior == input {
+			break
+		}
+		if slices.Contains(active, prior) {
+			values[prior.Name] = prior.Value.Get()
+		}
+	}
+	return values
+}
+
+// GenerateOptions returns the options of the input generated from its
+// OptionsFrom source.
+func (fs *FlagSet) GenerateOptions(input *Input) (InputOptions, error) {
+	if input.OptionsFrom == nil {
+		return input.Options, nil
+	}
+	options, err := input.OptionsFrom.Options(fs.PriorValues(input))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load options for input %s: %w", input.Name, err)
+	}
+	return options, nil
+}
+
+// Suggestions returns the suggestions of the input followed by those it
+// generates, without duplicates. The suggestions of the input are returned
+// with the error when they fail to generate.
+func (fs *FlagSet) Suggestions(input *Input) ([]string, error) {
+	suggestions := input.Suggestions
+	if input.SuggestionsFrom == nil {
+		return suggestions, nil
+	}
+	options, err := input.SuggestionsFrom.Options(fs.PriorValues(input))
+	if err != nil {
+		return suggestions, fmt.Errorf("failed to load suggestions for input %s: %w", input.Name, err)
+	}
+	for _, option := range options {
+		if option.Disabled != "" {
+			continue
+		}
+		suggestions = append(suggestions, option.Value)
+	}
+	return Unique(suggestions), nil
+}
+
+// Unique returns the strings without duplicates, keeping the first of each.
+func Unique(strs []string) []string {
+	seen := make(map[string]bool, len(strs))
+	unique := make([]string, 0, len(strs))
+	for _, s := range strs {
+		if !seen[s] {
+			seen[s] = true
+			unique = append(unique, s)
+		}
+	}
+	return unique
+}
+
+// CheckRules checks the value of the input against its rules, in order,
+// returning the error of the first it fails.
+func (fs *FlagSet) CheckRules(input *Input) error {
+	if len(input.Rules) == 0 {
+		return nil
+	}
+	values := fs.PriorValues(input)
+	for _, rule := range input.Rules {
+		if err := rule.Check(input.Value, values); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Confirm sets the value of the input with set, then checks it against the
+// input's rules and applies its transforms, unless it's a recorded value. The
+// input keeps its previous value when any of them fails, so a rejected value is
+// never used.
+func (fs *FlagSet) Confirm(input *Input, set func() error) error {
+	previous, raw := cloneValue(input.Value), input.raw
+	input.raw = nil
+	err := set()
+	if err == nil && input.recorded() {
+		return nil
+	}
+	if err == nil {
+		err = fs.CheckRules(input)
+	}
+	if err == nil {
+		err = fs.Transform(input)
+	}
+	if err != nil {
+		restoreValue(input.Value, previous)
+		input.raw = raw
+	}
+	return err
+}
+
+// Transform applies the transforms of the input to its value, or to each of
+// its items for lists, in order. The value is transformed from its raw value
+// each time, so transforming it again gives the same value. Recorded values are
+// already transformed, so are left as is.
+func (fs *FlagSet) Transform(input *Input) error {
+	if len(input.Transforms) == 0 || input.recorded() {
+		return nil
+	}
+	if input.raw == nil {
+		input.raw = cloneValue(input.Value)
+	} else {
+		restoreValue(input.Value, input.raw)
+	}
+	values := fs.PriorValues(input)
+	transform := func(s string) (string, error) {
+		for _, t := range input.Transforms {
+			var err error
+			if s, err = t.Transform(s, values); err != nil {
+				return "", err
+			}
+		}
+		return s, nil
+	}
+	if list, ok := input.Value.(*ListValue); ok {
+		items := make([]string, len(list.Values))
+		for i, item := range list.Values {
+			var err error
+			if items[i], err = transform(item); err != nil {
+				return err
+			}
+		}
+		return list.SetItems(items)
+	}
+	s, err := transform(input.Value.String())
+	if err != nil {
+		return err
+	}
+	return input.Value.Set(s)
+}
+
+// ValidateLive checks a value being typed for the input, without setting it,
+// with the input's live validation and the rules quick enough to check as it's
+// typed. Values that are valid so far but not yet complete aren't checked
+// against the rules.
+func (fs *FlagSet) ValidateLive(input *Input, s string) error {
+	value := cloneValue(input.Value)
+	var err error
+	if validator, ok := value.(LiveValidator); ok {
+		err = validator.ValidateLive(s)
+	} else {
+		err = value.Set(s)
+	}
+	if err != nil || value.Set(s) != nil {
+		return err
+	}
+	var values map[string]any
+	for _, rule := range input.Rules {
+		if live, ok := rule.(LiveRule); ok {
+			if values == nil {
+				values = fs.PriorValues(input)
+			}
+			if err := live.CheckLive(value, values); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// ApplyDefault sets the value of the input to the default rendered from its
+// DefaultFrom source. An empty default leaves the value as is.
+func (fs *FlagSet) ApplyDefault(input *Input) error {
+	if input.DefaultFrom == nil {
+		return nil
+	}
+	value, err := input.DefaultFrom.Default(fs.PriorValues(input))
+	if err == nil && value != "" {
+		if err = input.Value.Set(value); err == nil {
+			input.raw = nil
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("invalid default for input %s: %w", input.Name, err)
+	}
+	return nil
+}
+
+// Applies reports whether the condition of the input applies to the current
+// values. Inputs not in the FlagSet always apply.
+func (fs *FlagSet) Applies(input *Input) (bool, error) {
+	if input.When == nil || !slices.Contains(fs.inputs, input) {
+		return true, nil
+	}
+	active, err := fs.Active()
+	return slices.Contains(active, input), err
+}
+
+// Applicable filters the inputs to those whose conditions apply.
+func (fs *FlagSet) Applicable(inputs []*Input) ([]*Input, error) {
+	active, err := fs.Active()
+	if err != nil {
+		return nil, err
+	}
+	var applicable []*Input
+	for _, input := range inputs {
+		if input.When == nil || slices.Contains(active, input) {
+			applicable = append(applicable, input)
+		}
+	}
+	return applicable, nil
+}
+
+// Required filters the inputs to those that must be given a value.
+func Required(inputs []*Input) []*Input {
+	var required []*Input
+	for _, input := range inputs {
+		if input.Required() {
+			required = append(required, input)
+		}
+	}
+	return required
+}
+
 func (fs *FlagSet) Values() map[string]any {
 	values := make(map[string]any, len(fs.inputs))
 	for _, input := range fs.inputs {
@@ -169,36 +870,98 @@ func (fs *FlagSet) Values() map[string]any {
 }
 
 func (fs *FlagSet) ToEnvMap() map[string]string {
-	em := make(map[string]string, len(fs.inputs))
-	for _, input := range fs.inputs {
-		envName := fs.envPrefix + input.EnvName()
-		em[envName] = input.Value.String()
+	active, _ := fs.Active()
+	em := make(map[string]string, len(active))
+	for _, input := range active {
+		envName := fs.EnvVar(input)
+		if list, ok := input.Value.(*ListValue); ok {
+			em[envName] = list.EnvString()
+		} else {
+			em[envName] = input.Value.String()
+		}
 	}
 	return em
 }
 
+// ArgValues returns the values given to the inputs by options in args, in the
+// form ToArgs gives them, keyed by input name. Other arguments are ignored.
+func (fs *FlagSet) ArgValues(args []string) map[string]string {
+	p := argParser{fs: fs}
+	values := make(map[string]string)
+	for _, arg := range args {
+		if arg == "--" {
+			break
+		}
+		body, isOption := strings.CutPrefix(arg, "--")
+		if !isOption {
+			continue
+		}
+		name, value, hasValue := strings.Cut(body, "=")
+		if input := p.lookup(name); input == nil {
+			if negated := p.lookup(strings.TrimPrefix(name, negatePrefix)); negated != nil && isBoolean(negated) && !hasValue {
+				values[negated.Name] = "false"
+			}
+		} else if hasValue {
+			if input.Multiline() {
+				value = strings.TrimPrefix(value, FileRefPrefix)
+			}
+			values[input.Name] = value
+		} else if isBoolean(input) {
+			values[input.Name] = "true"
+		}
+	}
+	return values
+}
+
+// ToArgs returns the options giving the active inputs their values, leaving
+// out secret inputs.
 func (fs *FlagSet) ToArgs() []string {
-	args := make([]string, 0, len(fs.inputs))
-	for _, input := range fs.inputs {
+	active, _ := fs.Active()
+	args := make([]string, 0, len(active))
+	for _, input := range active {
+		if input.Secret {
+			continue
+		}
 		if v, ok := input.Value.(*BooleanValue); ok {
 			if v.Value {
-				args = append(args, fmt.Sprintf("-%s", input.Name))
+				args = append(args, input.Option())
 			} else {
-				args = append(args, fmt.Sprintf("-%s=%s", input.Name, v.String()))
+				args = append(args, "--"+negatePrefix+input.Name)
 			}
+		} else if input.Multiline() {
+			args = append(args, fmt.Sprintf("%s=%s", input.Option(), escapeFileRef(input.Value.String())))
 		} else {
-			args = append(args, fmt.Sprintf("-%s", input.Name))
-			args = append(args, input.Value.String())
+			args = append(args, fmt.Sprintf("%s=%s", input.Option(), input.Value.String()))
 		}
 	}
 	return args
 }
 
+// ToValues returns the values of the active inputs, leaving out secret inputs,
+// in the form a values file gives them.
+func (fs *FlagSet) ToValues() map[string]any {
+	active, _ := fs.Active()
+	values := make(map[string]any, len(active))
+	for _, input := range active {
+		if input.Secret {
+			continue
+		}
+		switch v := input.Value.(type) {
+		case *BooleanValue, *NumberValue, *IntegerValue, *ListValue:
+			values[input.Name] = v.Get()
+		default:
+			values[input.Name] = v.String()
+		}
+	}
+	return values
+}
+
 func (fs *FlagSet) Merge(other *FlagSet) *FlagSet {
 	merged := NewFlagSet(fs.name, fs.envPrefix)
 
 	for _, input := range fs.inputs {
 		merged.Var(input)
+		merged.keepEnvPrefix(input, fs.envPrefixOf(input))
 	}
 	for _, input := range other.inputs {
 		found := false
@@ -212,10 +975,21 @@ func (fs *FlagSet) Merge(other *FlagSet) *FlagSet {
 		if !found {
 			merged.Var(input)
 		}
+		merged.keepEnvPrefix(input, other.envPrefixOf(input))
 	}
 	return merged
 }
 
+// keepEnvPrefix records the prefix of a merged input when it differs from the
+// FlagSet's own.
+func (fs *FlagSet) keepEnvPrefix(input *Input, prefix string) {
+	if prefix == fs.envPrefix {
+		delete(fs.envPrefixes, input.Name)
+	} else {
+		fs.envPrefixes[input.Name] = prefix
+	}
+}
+
 func (fs *FlagSet) Has(name string) bool {
 	return slices.ContainsFunc(fs.inputs, func(input *Input) bool {
 		return input.Name == name
@@ -1,20 +1,88 @@
 package inputs
 
 import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
 
 func TestInputOptionsContains(t *testing.T) {
 	options := InputOptions{
-		{"A", "a"},
-		{"B", "b"},
+		{Label: "A", Value: "a"},
+		{Label: "B", Value: "b"},
 	}
 	assert.True(t, options.Contains("a"))
 	assert.False(t, options.Contains("c"))
 }
 
+func TestInputOptionsResolve(t *testing.T) {
+	months := InputOptions{
+		{Label: "January", Value: "1"},
+		{Label: "February", Value: "2"},
+		{Label: "March", Value: "3"},
+	}
+
+	t.Run("by value", func(t *testing.T) {
+		value, err := months.Resolve("2")
+		assert.NoError(t, err)
+		assert.Equal(t, "2", value)
+	})
+
+	t.Run("by label", func(t *testing.T) {
+		value, err := months.Resolve("February")
+		assert.NoError(t, err)
+		assert.Equal(t, "2", value)
+	})
+
+	t.Run("case insensitive", func(t *testing.T) {
+		value, err := months.Resolve("march")
+		assert.NoError(t, err)
+		assert.Equal(t, "3", value)
+	})
+
+	t.Run("values before labels", func(t *testing.T) {
+		value, err := InputOptions{{Label: "b", Value: "a"}, {Label: "a", Value: "b"}}.Resolve("a")
+		assert.NoError(t, err)
+		assert.Equal(t, "a", value)
+	})
+
+	t.Run("suggestions", func(t *testing.T) {
+		_, err := months.Resolve("Febuary")
+		assert.ErrorIs(t, err, ErrNotAnOption)
+		assert.EqualError(t, err, `not one of the options, did you mean "February"?`)
+	})
+
+	t.Run("no close options", func(t *testing.T) {
+		_, err := months.Resolve("foo")
+		assert.EqualError(t, err, "not one of the options: 1, 2, 3")
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		options := append(InputOptions{{Label: "December", Value: "12", Disabled: "Closed for the holidays"}}, months...)
+		_, err := options.Resolve("december")
+		assert.ErrorIs(t, err, ErrDisabledOption)
+		assert.EqualError(t, err, "disabled option December: Closed for the holidays")
+		_, err = options.Resolve("foo")
+		assert.EqualError(t, err, "not one of the options: 1, 2, 3")
+	})
+}
+
+func TestInputOptionsSuggest(t *testing.T) {
+	options := InputOptions{{Label: "staging", Value: "staging"}, {Label: "stage", Value: "stage"}, {Label: "production", Value: "production"}}
+	assert.Equal(t, []string{"stage", "staging"}, options.Suggest("stag"))
+	assert.Equal(t, []string{"production"}, options.Suggest("prod-uction"))
+	assert.Empty(t, options.Suggest("dev"))
+	options[0].Disabled = "Being rebuilt"
+	assert.Equal(t, []string{"stage"}, options.Suggest("stag"), "disabled options aren't suggested")
+}
+
 func TestStringValue(t *testing.T) {
 	t.Run("get and string", func(t *testing.T) {
 		v := StringValue{Value: "foobar"}
@@ -33,6 +101,47 @@ func TestStringValue(t *testing.T) {
 		assert.NoError(t, v.Set("foobar"))
 		assert.Equal(t, "foobar", v.Value)
 		assert.Error(t, v.Set("foobaz"))
+		assert.EqualError(t, v.Set("foobaz"), "invalid value: must match bar$")
+	})
+
+	t.Run("with lengths", func(t *testing.T) {
+		v := StringValue{MinLength: 2, MaxLength: 4}
+		assert.NoError(t, v.Set("héé"))
+		err := v.Set("a")
+		assert.ErrorIs(t, err, ErrInvalidValue)
+		assert.EqualError(t, err, "invalid value: must be at least 2 characters")
+		assert.EqualError(t, v.Set("abcde"), "invalid value: must be at most 4 characters")
+		assert.Equal(t, "héé", v.Value)
+	})
+
+	t.Run("with format", func(t *testing.T) {
+		formats := map[string][2][]string{
+			"email":    {{"name@example.com"}, {"Name <name@example.com>", "name"}},
+			"url":      {{"https://example.com/path", "ftp://host"}, {"example.com", "/path"}},
+			"hostname": {{"host.example.com", "localhost", "a-1.io."}, {"-host.com", "host_name", "a..b"}},
+			"ipv4":     {{"192.0.2.1"}, {"2001:db8::1", "256.0.0.1"}},
+			"ipv6":     {{"2001:db8::1", "::1"}, {"192.0.2.1", "2001:db8::g"}},
+			"cidr":     {{"192.0.2.0/24", "2001:db8::/32"}, {"192.0.2.0", "192.0.2.0/33"}},
+			"semver":   {{"1.2.3", "1.0.0-rc.1+build.5"}, {"v1.2.3", "1.2", "01.2.3"}},
+			"uuid":     {{"123e4567-e89b-12d3-a456-426614174000"}, {"123e4567e89b12d3a456426614174000"}},
+			"port":     {{"1", "8080", "65535"}, {"0", "65536", "-1", "http"}},
+		}
+		for format, values := range formats {
+			v := StringValue{Format: format}
+			for _, valid := range values[0] {
+				assert.NoError(t, v.Set(valid), "%s %s", format, valid)
+			}
+			for _, invalid := range values[1] {
+				assert.ErrorIs(t, v.Set(invalid), ErrInvalidValue, "%s %s", format, invalid)
+			}
+		}
+		v := StringValue{Format: "email"}
+		assert.EqualError(t, v.Set("name"), "invalid value: must be an email address like name@example.com")
+		assert.Equal(t, "an email address like name@example.com", v.Hint())
+		assert.EqualError(t, v.ValidateLive("name@"), "invalid value: must be an email address like name@example.com")
+		assert.Equal(t, "", StringValue{}.Hint())
+		assert.True(t, IsStringFormat("uuid"))
+		assert.False(t, IsStringFormat("phone"))
 	})
 }
 
@@ -56,6 +165,68 @@ func TestNumberValue(t *testing.T) {
 		assert.Error(t, v.Set("0"))
 		assert.Error(t, v.Set("5.1"))
 	})
+
+	t.Run("with precision", func(t *testing.T) {
+		precision := 2
+		v := NumberValue{Precision: &precision}
+		assert.NoError(t, v.Set("1.236"))
+		assert.Equal(t, 1.24, v.Get())
+		assert.Equal(t, "1.24", v.String())
+		assert.NoError(t, v.Set("3"))
+		assert.Equal(t, "3.00", v.String())
+	})
+
+	t.Run("adjust by step", func(t *testing.T) {
+		precision := 1
+		v := NumberValue{Value: 0.5, Step: 0.1, MinValue: 0, MaxValue: 0.6, Precision: &precision}
+		newStr, err := v.Adjust("", 1)
+		assert.NoError(t, err)
+		assert.Equal(t, "0.6", newStr)
+		newStr, err = v.Adjust("0.6", 1)
+		assert.NoError(t, err)
+		assert.Equal(t, "0.6", newStr)
+		newStr, err = v.Adjust("0.6", -1)
+		assert.NoError(t, err)
+		assert.Equal(t, "0.5", newStr)
+	})
+}
+
+func TestIntegerValue(t *testing.T) {
+	t.Run("get and string", func(t *testing.T) {
+		v := IntegerValue{Value: 42}
+		assert.Equal(t, int64(42), v.Get())
+		assert.Equal(t, "42", v.String())
+	})
+
+	t.Run("set", func(t *testing.T) {
+		v := IntegerValue{}
+		assert.NoError(t, v.Set("-7"))
+		assert.Equal(t, int64(-7), v.Value)
+		assert.Error(t, v.Set("1.5"))
+		assert.Error(t, v.Set("nope"))
+	})
+
+	t.Run("with min and max", func(t *testing.T) {
+		v := IntegerValue{MinValue: 1, MaxValue: 5}
+		assert.NoError(t, v.Set("5"))
+		assert.ErrorIs(t, v.Set("0"), ErrInvalidValue)
+		assert.ErrorIs(t, v.Set("6"), ErrInvalidValue)
+		assert.NoError(t, v.ValidateLive("-"))
+		assert.Error(t, v.ValidateLive("9"))
+	})
+
+	t.Run("adjust by step", func(t *testing.T) {
+		v := IntegerValue{Value: 10, Step: 5, MinValue: 0, MaxValue: 20}
+		newStr, err := v.Adjust("", 1)
+		assert.NoError(t, err)
+		assert.Equal(t, "15", newStr)
+		newStr, err = v.Adjust("18", 1)
+		assert.NoError(t, err)
+		assert.Equal(t, "20", newStr)
+		newStr, err = v.Adjust("3", -1)
+		assert.NoError(t, err)
+		assert.Equal(t, "0", newStr)
+	})
 }
 
 func TestBooleanValue(t *testing.T) {
@@ -79,6 +250,232 @@ func TestBooleanValue(t *testing.T) {
 	})
 }
 
+func TestListValue(t *testing.T) {
+	t.Run("get and string", func(t *testing.T) {
+		v := ListValue{Values: []string{"api", "web"}}
+		assert.Equal(t, []string{"api", "web"}, v.Get())
+		assert.Equal(t, "api,web", v.String())
+	})
+
+	t.Run("set comma separated", func(t *testing.T) {
+		v := ListValue{}
+		assert.NoError(t, v.Set(" api, web,,"))
+		assert.Equal(t, []string{"api", "web"}, v.Values)
+		assert.NoError(t, v.Set(""))
+		assert.Equal(t, []string{}, v.Values)
+	})
+
+	t.Run("min and max items", func(t *testing.T) {
+		v := ListValue{MinItems: 1, MaxItems: 2}
+		assert.EqualError(t, v.Set(""), "invalid value: expected at least 1 items, got 0")
+		assert.EqualError(t, v.Set("a,b,c"), "invalid value: expected at most 2 items, got 3")
+		assert.NoError(t, v.ValidateLive("a,b"))
+		assert.Error(t, v.ValidateLive("a,b,c"))
+	})
+
+	t.Run("separator", func(t *testing.T) {
+		v := ListValue{Values: []string{"api", "web"}, Separator: ":"}
+		assert.Equal(t, "api:web", v.EnvString())
+		assert.Equal(t, []string{"a", "b,c"}, v.SplitEnv("a:b,c"))
+		assert.Equal(t, "api,web", ListValue{Values: []string{"api", "web"}}.EnvString())
+	})
+}
+
+func TestPathValue(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.Mkdir(filepath.Join(dir, "src"), 0o755))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "main.go"), nil, 0o644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), nil, 0o644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, ".env"), nil, 0o644))
+
+	t.Run("get is absolute", func(t *testing.T) {
+		v := PathValue{Value: "src/../main.go", Base: dir}
+		assert.Equal(t, "src/../main.go", v.String())
+		assert.Equal(t, filepath.Join(dir, "main.go"), v.Get())
+		assert.Equal(t, "/tmp", PathValue{Value: "/tmp", Base: dir}.Get())
+		assert.Equal(t, "", PathValue{Base: dir}.Get())
+		cwd, _ := os.Getwd()
+		assert.Equal(t, filepath.Join(cwd, "main.go"), PathValue{Value: "main.go"}.Get())
+	})
+
+	t.Run("must exist", func(t *testing.T) {
+		v := PathValue{Base: dir, MustExist: true}
+		assert.NoError(t, v.Set("main.go"))
+		assert.Equal(t, "main.go", v.Value)
+		assert.EqualError(t, v.Set("missing.go"), "invalid value: missing.go does not exist")
+		assert.ErrorIs(t, v.Set(""), ErrInvalidValue)
+		assert.Equal(t, "main.go", v.Value)
+		assert.NoError(t, (&PathValue{Base: dir}).Set("missing.go"))
+	})
+
+	t.Run("kind", func(t *testing.T) {
+		file := PathValue{Base: dir, Kind: PathKindFile}
+		assert.NoError(t, file.Set("main.go"))
+		assert.EqualError(t, file.Set("src"), "invalid value: src is a directory")
+		folder := PathValue{Base: dir, Kind: PathKindDir}
+		assert.NoError(t, folder.Set("src"))
+		assert.EqualError(t, folder.Set("main.go"), "invalid value: main.go is not a directory")
+	})
+
+	t.Run("extensions", func(t *testing.T) {
+		v := PathValue{Base: dir, Extensions: []string{"go", ".MD"}}
+		assert.NoError(t, v.Set("main.go"))
+		assert.NoError(t, v.Set("README.md"))
+		assert.NoError(t, v.Set("src"))
+		assert.EqualError(t, v.Set("main.txt"), "invalid value: main.txt must end with go, .MD")
+		assert.Error(t, v.ValidateLive("main.txt"))
+	})
+
+	t.Run("complete", func(t *testing.T) {
+		v := PathValue{Base: dir}
+		assert.Equal(t, []string{"README.md", "main.go", "src/"}, v.Complete(""))
+		assert.Equal(t, []string{"main.go"}, v.Complete("ma"))
+		assert.Equal(t, []string{".env"}, v.Complete("."))
+		assert.Equal(t, []string{"src/"}, PathValue{Base: dir, Kind: PathKindDir}.Complete(""))
+		assert.Equal(t, []string{"main.go", "src/"}, PathValue{Base: dir, Extensions: []string{"go"}}.Complete(""))
+		assert.Equal(t, []string{dir + "/main.go"}, v.Complete(dir+"/m"))
+		assert.Empty(t, v.Complete("missing/"))
+	})
+}
+
+func TestDateValue(t *testing.T) {
+	defer func(clock func() time.Time) { now = clock }(now)
+	now = func() time.Time { return time.Date(2024, 3, 10, 14, 30, 45, 0, time.Local) }
+
+	t.Run("date", func(t *testing.T) {
+		v := DateValue{}
+		assert.NoError(t, v.Set("2024-05-01"))
+		assert.Equal(t, time.Date(2024, 5, 1, 0, 0, 0, 0, time.Local), v.Get())
+		assert.Equal(t, "2024-05-01", v.String())
+		assert.EqualError(t, v.Set("01/05/2024"), "invalid value: expected a date like 2024-03-10")
+		assert.NoError(t, v.Set(""))
+		assert.Equal(t, "", v.String())
+	})
+
+	t.Run("relative", func(t *testing.T) {
+		v := DateValue{}
+		for s, expected := range map[string]string{
+			"today":      "2024-03-10",
+			"today+7d":   "2024-03-17",
+			"Tomorrow":   "2024-03-11",
+			"yesterday":  "2024-03-09",
+			"now - 1w":   "2024-03-03",
+			"today+1d1h": "2024-03-11",
+		} {
+			assert.NoError(t, v.Set(s), s)
+			assert.Equal(t, expected, v.String(), s)
+		}
+		assert.Error(t, v.Set("today+1x"))
+	})
+
+	t.Run("datetime and format", func(t *testing.T) {
+		v := DateValue{WithTime: true}
+		assert.NoError(t, v.Set("now+2h"))
+		assert.Equal(t, "2024-03-10 16:30", v.String())
+		v = DateValue{Format: "02/01/2006 3PM"}
+		assert.NoError(t, v.Set("01/05/2024 9AM"))
+		assert.Equal(t, time.Date(2024, 5, 1, 9, 0, 0, 0, time.Local), v.Get())
+	})
+
+	t.Run("min and max", func(t *testing.T) {
+		v := DateValue{MinValue: "today", MaxValue: "today+7d"}
+		assert.NoError(t, v.Set("2024-03-10"))
+		assert.EqualError(t, v.Set("2024-03-09"), "invalid value: must not be before 2024-03-10")
+		assert.EqualError(t, v.Set("2024-03-18"), "invalid value: must not be after 2024-03-17")
+		assert.Error(t, v.ValidateLive("2024-03-18"))
+		assert.Equal(t, time.Date(2024, 3, 10, 0, 0, 0, 0, time.Local), v.Value)
+	})
+
+	t.Run("adjust", func(t *testing.T) {
+		v := DateValue{MaxValue: "2024-03-11"}
+		s, err := v.Adjust("", 1)
+		assert.NoError(t, err)
+		assert.Equal(t, "2024-03-11", s)
+		s, _ = v.Adjust(s, 1)
+		assert.Equal(t, "2024-03-11", s)
+		s, _ = v.Adjust(s, -1)
+		assert.Equal(t, "2024-03-10", s)
+		dt := DateValue{WithTime: true}
+		s, _ = dt.Adjust("2024-03-10 23:00", 1)
+		assert.Equal(t, "2024-03-11 00:00", s)
+	})
+}
+
+func TestDurationValue(t *testing.T) {
+	t.Run("set and get", func(t *testing.T) {
+		v := DurationValue{}
+		assert.NoError(t, v.Set("1h30m"))
+		assert.Equal(t, 90*time.Minute, v.Get())
+		assert.Equal(t, "1h30m", v.String())
+		assert.NoError(t, v.Set("1w2d"))
+		assert.Equal(t, 9*24*time.Hour, v.Value)
+		assert.Equal(t, "216h", v.String())
+		assert.NoError(t, v.Set("1d12h"))
+		assert.Equal(t, 36*time.Hour, v.Value)
+		assert.Equal(t, "45s", DurationValue{Value: 45 * time.Second}.String())
+		assert.Equal(t, "10m", DurationValue{Value: 10 * time.Minute}.String())
+		assert.ErrorIs(t, v.Set("soon"), ErrInvalidValue)
+		assert.ErrorIs(t, v.Set("1d2x"), ErrInvalidValue)
+	})
+
+	t.Run("min and max", func(t *testing.T) {
+		v := DurationValue{MinValue: "30s", MaxValue: "1d"}
+		assert.NoError(t, v.Set("1h"))
+		assert.EqualError(t, v.Set("10s"), "invalid value: must be at least 30s")
+		assert.EqualError(t, v.Set("25h"), "invalid value: must be at most 24h")
+	})
+
+	t.Run("adjust", func(t *testing.T) {
+		v := DurationValue{Value: time.Minute}
+		s, err := v.Adjust("", 1)
+		assert.NoError(t, err)
+		assert.Equal(t, "2m", s)
+		s, _ = v.Adjust("1m", -2)
+		assert.Equal(t, "0s", s)
+		stepped := DurationValue{Step: "1h", MaxValue: "2h"}
+		s, _ = stepped.Adjust("90m", 1)
+		assert.Equal(t, "2h", s)
+	})
+}
+
+func TestFlagSet_ParseList(t *testing.T) {
+	newFlagSet := func(list *ListValue, options InputOptions) *FlagSet {
+		fs := NewFlagSet("test", "ILC_INPUT_")
+		fs.Var(&Input{Name: "svc", Short: "s", Options: options, Value: list})
+		return fs
+	}
+
+	t.Run("repeated options", func(t *testing.T) {
+		list := &ListValue{}
+		assert.NoError(t, newFlagSet(list, nil).Parse([]string{"-svc", "api", "-s", "web,db", "--svc=cache"}, nil, true))
+		assert.Equal(t, []string{"api", "web", "db", "cache"}, list.Values)
+	})
+
+	t.Run("environment separator", func(t *testing.T) {
+		list := &ListValue{Separator: " "}
+		fs := newFlagSet(list, nil)
+		assert.NoError(t, fs.Parse(nil, map[string]string{"ILC_INPUT_SVC": "api web"}, true))
+		assert.Equal(t, []string{"api", "web"}, list.Values)
+		assert.Equal(t, map[string]string{"ILC_INPUT_SVC": "api web"}, fs.ToEnvMap())
+		assert.Equal(t, []string{"--svc=api,web"}, fs.ToArgs())
+	})
+
+	t.Run("options", func(t *testing.T) {
+		list := &ListValue{}
+		options := InputOptions{{Label: "API", Value: "api"}, {Label: "Web", Value: "web"}}
+		assert.NoError(t, newFlagSet(list, options).Parse([]string{"-svc", "API", "-svc", "web"}, nil, true))
+		assert.Equal(t, []string{"api", "web"}, list.Values)
+
+		err := newFlagSet(&ListValue{}, options).Parse([]string{"-svc", "api,wev"}, nil, true)
+		assert.EqualError(t, err, `invalid value "api,wev" for input --svc: wev: not one of the options, did you mean "web"?`)
+	})
+
+	t.Run("too many items", func(t *testing.T) {
+		err := newFlagSet(&ListValue{MaxItems: 1}, nil).Parse([]string{"-svc", "api", "-svc", "web"}, nil, true)
+		assert.EqualError(t, err, `invalid value "api,web" for input --svc: invalid value: expected at most 1 items, got 2`)
+	})
+}
+
 func TestFlagSet_Parse(t *testing.T) {
 	t.Run("parse from environment", func(t *testing.T) {
 		fs := NewFlagSet("test", "ILC_INPUT_")
@@ -141,6 +538,29 @@ func TestFlagSet_Merge(t *testing.T) {
 	assert.Equal(t, 2, len(merged.inputs))
 }
 
+func TestFlagSet_EnvVar(t *testing.T) {
+	region := &Input{Name: "aws-region", Value: &StringValue{}}
+	tag := &Input{Name: "tag", Env: "IMAGE_TAG", Value: &StringValue{}}
+	env := &Input{Name: "env", Value: &StringValue{}}
+	deploy := NewFlagSet("test", "ILC_INPUT_")
+	deploy.Var(region)
+	deploy.Var(tag)
+	root := NewFlagSet("test", "ILC_INPUT_")
+	root.Var(env)
+
+	fs := root.Merge(deploy.WithEnvPrefix(""))
+	assert.Equal(t, "ILC_INPUT_ENV", fs.EnvVar(env))
+	assert.Equal(t, "AWS_REGION", fs.EnvVar(region))
+	assert.Equal(t, "IMAGE_TAG", fs.EnvVar(tag))
+	assert.Equal(t, "AWS_REGION", fs.WithEnvPrefix("APP_").EnvVar(region))
+	assert.Equal(t, "APP_ENV", fs.WithEnvPrefix("APP_").EnvVar(env))
+
+	envs := map[string]string{"ILC_INPUT_ENV": "prod", "AWS_REGION": "eu", "IMAGE_TAG": "v1", "ILC_INPUT_TAG": "v2"}
+	assert.NoError(t, fs.Parse(nil, envs, true))
+	assert.Equal(t, "v1", tag.Value.String())
+	assert.Equal(t, map[string]string{"ILC_INPUT_ENV": "prod", "AWS_REGION": "eu", "IMAGE_TAG": "v1"}, fs.ToEnvMap())
+}
+
 type MockPrompter struct {
 	PromptFunc    func(title string, missing []*Input) error
 	Called        bool
@@ -216,10 +636,10 @@ func TestFlagSet_InputsValuesToEnvMapToArgs(t *testing.T) {
 
 	// Test ToArgs()
 	args := fs.ToArgs()
-	assert.Contains(t, args, "-str")
-	assert.Contains(t, args, "hello")
-	assert.Contains(t, args, "-bool-true")
-	assert.Contains(t, args, "-bool-false=false")
+	assert.Contains(t, args, "--str=hello")
+	assert.Contains(t, args, "--num=42")
+	assert.Contains(t, args, "--bool-true")
+	assert.Contains(t, args, "--no-bool-false")
 }
 
 func TestValues_ValidateLive(t *testing.T) {
@@ -245,3 +665,751 @@ func TestValues_ValidateLive(t *testing.T) {
 	assert.Error(t, bVal.ValidateLive("nope"))
 }
 
+
+func TestFlagSet_ParsePositional(t *testing.T) {
+	newFlagSet := func() (*FlagSet, *StringValue, *StringValue) {
+		fs := NewFlagSet("test", "ILC_INPUT_")
+		env := &StringValue{}
+		service := &StringValue{}
+		fs.Var(&Input{Name: "service", Position: 2, Value: service})
+		fs.Var(&Input{Name: "env", Position: 1, Value: env})
+		fs.Var(&Input{Name: "force", Value: &BooleanValue{}})
+		return fs, env, service
+	}
+
+	t.Run("bare arguments in order", func(t *testing.T) {
+		fs, env, service := newFlagSet()
+		missing, err := fs.ParseEnvAndArgs([]string{"prod", "api", "-force"}, nil)
+		assert.NoError(t, err)
+		assert.Empty(t, missing)
+		assert.Equal(t, "prod", env.Value)
+		assert.Equal(t, "api", service.Value)
+		assert.Empty(t, fs.Args())
+	})
+
+	t.Run("skips inputs given as flags", func(t *testing.T) {
+		fs, env, service := newFlagSet()
+		_, err := fs.ParseEnvAndArgs([]string{"api", "-env", "prod"}, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, "prod", env.Value)
+		assert.Equal(t, "api", service.Value)
+	})
+
+	t.Run("environment does not consume position", func(t *testing.T) {
+		fs, env, service := newFlagSet()
+		_, err := fs.ParseEnvAndArgs([]string{"prod"}, map[string]string{"ILC_INPUT_SERVICE": "web"})
+		assert.NoError(t, err)
+		assert.Equal(t, "prod", env.Value)
+		assert.Equal(t, "web", service.Value)
+	})
+
+	t.Run("remaining arguments", func(t *testing.T) {
+		fs, _, _ := newFlagSet()
+		_, err := fs.ParseEnvAndArgs([]string{"prod", "api", "extra", "--", "-more"}, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"extra", "-more"}, fs.Args())
+	})
+}
+
+func TestFlagSet_ParseOptions(t *testing.T) {
+	newFlagSet := func() (*FlagSet, *StringValue, *BooleanValue, *BooleanValue) {
+		fs := NewFlagSet("test", "ILC_INPUT_")
+		env := &StringValue{}
+		force := &BooleanValue{}
+		verbose := &BooleanValue{Value: true}
+		fs.Var(&Input{Name: "env", Short: "e", Value: env})
+		fs.Var(&Input{Name: "force", Short: "f", Value: force})
+		fs.Var(&Input{Name: "verbose", Short: "v", Value: verbose})
+		return fs, env, force, verbose
+	}
+
+	tests := []struct {
+		name    string
+		args    []string
+		env     string
+		force   bool
+		verbose bool
+	}{
+		{"single dash long", []string{"-env", "prod", "-force"}, "prod", true, true},
+		{"double dash long", []string{"--env", "prod", "--force"}, "prod", true, true},
+		{"long with equals", []string{"--env=prod", "--force=true", "--verbose=false"}, "prod", true, false},
+		{"negated boolean", []string{"--env=prod", "--no-verbose"}, "prod", false, false},
+		{"short options", []string{"-e", "prod", "-f"}, "prod", true, true},
+		{"short attached value", []string{"-eprod"}, "prod", false, true},
+		{"combined short booleans", []string{"-fv", "-e=prod"}, "prod", true, true},
+		{"combined short with value", []string{"-fe", "prod"}, "prod", true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs, env, force, verbose := newFlagSet()
+			missing, err := fs.ParseEnvAndArgs(tt.args, nil)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.env, env.Value)
+			assert.Equal(t, tt.force, force.Value)
+			assert.Equal(t, tt.verbose, verbose.Value)
+			assert.NotContains(t, missing, fs.inputs[0])
+		})
+	}
+
+	t.Run("unknown option", func(t *testing.T) {
+		fs, _, _, _ := newFlagSet()
+		_, err := fs.ParseEnvAndArgs([]string{"--nope"}, nil)
+		assert.EqualError(t, err, "unknown input: --nope")
+		_, err = fs.ParseEnvAndArgs([]string{"-fx"}, nil)
+		assert.EqualError(t, err, "unknown input: -x")
+	})
+
+	t.Run("missing value", func(t *testing.T) {
+		fs, _, _, _ := newFlagSet()
+		_, err := fs.ParseEnvAndArgs([]string{"--env"}, nil)
+		assert.EqualError(t, err, "input --env requires a value")
+	})
+
+	t.Run("negated non-boolean", func(t *testing.T) {
+		fs, _, _, _ := newFlagSet()
+		_, err := fs.ParseEnvAndArgs([]string{"--no-env"}, nil)
+		assert.EqualError(t, err, "unknown input: --no-env")
+	})
+
+	t.Run("help", func(t *testing.T) {
+		fs, _, _, _ := newFlagSet()
+		_, err := fs.ParseEnvAndArgs([]string{"-h"}, nil)
+		assert.ErrorIs(t, err, flag.ErrHelp)
+		_, err = fs.ParseEnvAndArgs([]string{"--help"}, nil)
+		assert.ErrorIs(t, err, flag.ErrHelp)
+	})
+
+	t.Run("round trips canonical form", func(t *testing.T) {
+		fs, _, _, _ := newFlagSet()
+		_, err := fs.ParseEnvAndArgs([]string{"-e", "prod", "-f", "--no-verbose"}, nil)
+		assert.NoError(t, err)
+		args := fs.ToArgs()
+		assert.Equal(t, []string{"--env=prod", "--force", "--no-verbose"}, args)
+
+		other, env, force, verbose := newFlagSet()
+		verbose.Value = true
+		_, err = other.ParseEnvAndArgs(args, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, "prod", env.Value)
+		assert.True(t, force.Value)
+		assert.False(t, verbose.Value)
+	})
+}
+
+func TestInputOptionNames(t *testing.T) {
+	assert.Equal(t, []string{"--env"}, Input{Name: "env", Value: &StringValue{}}.OptionNames())
+	assert.Equal(t, []string{"-e", "--env"}, Input{Name: "env", Short: "e", Value: &StringValue{}}.OptionNames())
+	assert.Equal(t, []string{"-f", "--[no-]force"}, Input{Name: "force", Short: "f", Value: &BooleanValue{}}.OptionNames())
+}
+
+func TestFlagSet_ArgValues(t *testing.T) {
+	fs := NewFlagSet("test", "ILC_INPUT_")
+	fs.Var(&Input{Name: "user", Value: &StringValue{}})
+	fs.Var(&Input{Name: "verbose", Value: &BooleanValue{}})
+	fs.Var(&Input{Name: "force", Value: &BooleanValue{}})
+	fs.Var(&Input{Name: "tags", Value: &ListValue{}})
+	fs.Var(&Input{Name: "notes", Value: &StringValue{Multiline: true}})
+	fs.Var(&Input{Name: "token", Secret: true, Value: &StringValue{}})
+	assert.NoError(t, fs.Parse([]string{"-user", "bob", "-verbose", "-no-force", "-tags", "a,b", "-notes", "@@here", "-token", "s3cret"}, nil, true))
+
+	expected := map[string]string{"user": "bob", "verbose": "true", "force": "false", "tags": "a,b", "notes": "@here"}
+	assert.Equal(t, expected, fs.ArgValues(fs.ToArgs()))
+	assert.Equal(t, map[string]string{"user": "amy"}, fs.ArgValues([]string{"deploy", "--user=amy", "--other=x", "--", "--verbose"}))
+}
+
+func TestFlagSet_OptionArgs(t *testing.T) {
+	fs := NewFlagSet("test", "ILC_INPUT_")
+	fs.Var(&Input{Name: "env", Short: "e", Value: &StringValue{}})
+	fs.Var(&Input{Name: "force", Short: "f", Value: &BooleanValue{}})
+
+	tests := []struct {
+		args     []string
+		expected int
+	}{
+		{[]string{"-env", "prod"}, 2},
+		{[]string{"--env", "prod"}, 2},
+		{[]string{"--env=prod", "x"}, 1},
+		{[]string{"--env"}, 1},
+		{[]string{"--force", "x"}, 1},
+		{[]string{"--no-force", "x"}, 1},
+		{[]string{"-e", "prod"}, 2},
+		{[]string{"-eprod", "x"}, 1},
+		{[]string{"-fe", "prod"}, 2},
+		{[]string{"-ff", "x"}, 1},
+		{[]string{"-f=false", "x"}, 1},
+		{[]string{"--nope", "x"}, 0},
+		{[]string{"-x", "x"}, 0},
+		{[]string{"--", "x"}, 0},
+		{[]string{"bare"}, 0},
+		{[]string{}, 0},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, fs.OptionArgs(tt.args), "OptionArgs(%v)", tt.args)
+	}
+}
+
+func TestFlagSet_ParseOptional(t *testing.T) {
+	t.Run("optional inputs are not required in non-interactive", func(t *testing.T) {
+		fs := NewFlagSet("test", "ILC_INPUT_")
+		fs.Var(&Input{Name: "airport", Optional: true, Value: &StringValue{Value: "bne"}})
+		assert.NoError(t, fs.Parse(nil, nil, true))
+	})
+
+	t.Run("only required inputs are prompted when accepting defaults", func(t *testing.T) {
+		fs := NewFlagSet("test", "ILC_INPUT_")
+		fs.Var(&Input{Name: "airport", Optional: true, Value: &StringValue{Value: "bne"}})
+		fs.Var(&Input{Name: "airline", Value: &StringValue{}})
+		mock := &MockPrompter{}
+		fs.Prompter = mock
+		fs.AcceptDefaults = true
+		assert.NoError(t, fs.Parse(nil, nil, false))
+		assert.Len(t, mock.PassedMissing, 1)
+		assert.Equal(t, "airline", mock.PassedMissing[0].Name)
+	})
+}
+
+func TestRequired(t *testing.T) {
+	optional := &Input{Name: "optional", Optional: true}
+	required := &Input{Name: "required"}
+	assert.Equal(t, []*Input{required}, Required([]*Input{optional, required}))
+	assert.Empty(t, Required([]*Input{optional}))
+}
+
+type conditionFunc func(values map[string]any) (bool, error)
+
+func (f conditionFunc) Evaluate(values map[string]any) (bool, error) {
+	return f(values)
+}
+
+func whenEquals(name string, value any) Condition {
+	return conditionFunc(func(values map[string]any) (bool, error) {
+		return values[name] == value, nil
+	})
+}
+
+func TestFlagSet_Active(t *testing.T) {
+	source := &Input{Name: "source", Value: &StringValue{Value: "local"}}
+	tag := &Input{Name: "tag", When: whenEquals("source", "registry"), Value: &StringValue{Value: "latest"}}
+	digest := &Input{Name: "digest", When: whenEquals("tag", "latest"), Value: &StringValue{Value: "sha"}}
+	fs := NewFlagSet("test", "ILC_INPUT_")
+	fs.Var(source)
+	fs.Var(tag)
+	fs.Var(digest)
+
+	t.Run("skips inputs whose conditions do not apply", func(t *testing.T) {
+		active, err := fs.Active()
+		assert.NoError(t, err)
+		// digest is skipped as it depends on the skipped tag input
+		assert.Equal(t, []*Input{source}, active)
+		assert.Equal(t, map[string]string{"ILC_INPUT_SOURCE": "local"}, fs.ToEnvMap())
+		assert.Equal(t, []string{"--source=local"}, fs.ToArgs())
+
+		applies, err := fs.Applies(tag)
+		assert.NoError(t, err)
+		assert.False(t, applies)
+	})
+
+	t.Run("includes inputs whose conditions apply", func(t *testing.T) {
+		source.Value.Set("registry")
+		defer source.Value.Set("local")
+		active, err := fs.Active()
+		assert.NoError(t, err)
+		assert.Equal(t, []*Input{source, tag, digest}, active)
+
+		applicable, err := fs.Applicable([]*Input{tag, digest})
+		assert.NoError(t, err)
+		assert.Equal(t, []*Input{tag, digest}, applicable)
+	})
+
+	t.Run("non-interactive does not require skipped inputs", func(t *testing.T) {
+		fs := NewFlagSet("test", "ILC_INPUT_")
+		fs.Var(&Input{Name: "source", Value: &StringValue{}})
+		fs.Var(&Input{Name: "tag", When: whenEquals("source", "registry"), Value: &StringValue{}})
+		assert.NoError(t, fs.Parse([]string{"-source", "local"}, nil, true))
+		assert.EqualError(t, fs.Parse([]string{"-source", "registry"}, nil, true), "missing inputs: tag")
+	})
+
+	t.Run("condition errors", func(t *testing.T) {
+		fs := NewFlagSet("test", "ILC_INPUT_")
+		fs.Var(&Input{Name: "broken", When: conditionFunc(func(map[string]any) (bool, error) {
+			return false, assert.AnError
+		}), Value: &StringValue{}})
+		active, err := fs.Active()
+		assert.Empty(t, active)
+		assert.ErrorIs(t, err, assert.AnError)
+		assert.ErrorContains(t, err, "invalid condition for input broken")
+	})
+}
+
+type optionsFunc func(values map[string]any) (InputOptions, error)
+
+func (f optionsFunc) Options(values map[string]any) (InputOptions, error) {
+	return f(values)
+}
+
+func TestFlagSet_OptionsFrom(t *testing.T) {
+	branches := optionsFunc(func(values map[string]any) (InputOptions, error) {
+		if values["remote"] == "upstream" {
+			return InputOptions{{Label: "main", Value: "main"}}, nil
+		}
+		return InputOptions{{Label: "main", Value: "main"}, {Label: "Feature", Value: "feature"}}, nil
+	})
+
+	t.Run("input is selectable", func(t *testing.T) {
+		assert.True(t, Input{OptionsFrom: branches}.Selectable())
+	})
+
+	t.Run("accepts generated values", func(t *testing.T) {
+		branch := &Input{Name: "branch", OptionsFrom: branches, Value: &StringValue{}}
+		fs := NewFlagSet("test", "ILC_INPUT_")
+		fs.Var(branch)
+		assert.NoError(t, fs.Parse([]string{"-branch", "feature"}, nil, true))
+		assert.Equal(t, InputOptions{{Label: "main", Value: "main"}, {Label: "Feature", Value: "feature"}}, branch.Options)
+	})
+
+	t.Run("generates options from prior values", func(t *testing.T) {
+		fs := NewFlagSet("test", "ILC_INPUT_")
+		fs.Var(&Input{Name: "remote", Value: &StringValue{}})
+		fs.Var(&Input{Name: "branch", OptionsFrom: branches, Value: &StringValue{}})
+		err := fs.Parse([]string{"-remote", "upstream", "-branch", "feature"}, nil, true)
+		assert.EqualError(t, err, `invalid value "feature" for input branch: not one of the options: main`)
+	})
+
+	t.Run("reports failures to generate", func(t *testing.T) {
+		failing := optionsFunc(func(values map[string]any) (InputOptions, error) {
+			return nil, assert.AnError
+		})
+		fs := NewFlagSet("test", "ILC_INPUT_")
+		fs.Var(&Input{Name: "branch", OptionsFrom: failing, Value: &StringValue{}})
+		err := fs.Parse([]string{"-branch", "main"}, nil, true)
+		assert.ErrorIs(t, err, assert.AnError)
+		assert.ErrorContains(t, err, "failed to load options for input branch")
+	})
+}
+
+type defaultFunc func(values map[string]any) (string, error)
+
+func (f defaultFunc) Default(values map[string]any) (string, error) {
+	return f(values)
+}
+
+func TestFlagSet_DefaultFrom(t *testing.T) {
+	replicasDefault := defaultFunc(func(values map[string]any) (string, error) {
+		if values["env"] == "production" {
+			return "3", nil
+		}
+		return "", nil
+	})
+
+	t.Run("renders from given values", func(t *testing.T) {
+		replicas := &Input{Name: "replicas", Optional: true, DefaultFrom: replicasDefault, Value: &NumberValue{Value: 1}}
+		fs := NewFlagSet("test", "ILC_INPUT_")
+		fs.Var(&Input{Name: "env", Value: &StringValue{}})
+		fs.Var(replicas)
+		assert.NoError(t, fs.Parse([]string{"-env", "production"}, nil, true))
+		assert.Equal(t, "3", replicas.Value.String())
+	})
+
+	t.Run("empty default keeps value", func(t *testing.T) {
+		replicas := &Input{Name: "replicas", Optional: true, DefaultFrom: replicasDefault, Value: &NumberValue{Value: 1}}
+		fs := NewFlagSet("test", "ILC_INPUT_")
+		fs.Var(&Input{Name: "env", Value: &StringValue{}})
+		fs.Var(replicas)
+		assert.NoError(t, fs.Parse([]string{"-env", "staging"}, nil, true))
+		assert.Equal(t, "1", replicas.Value.String())
+	})
+
+	t.Run("given values are kept", func(t *testing.T) {
+		replicas := &Input{Name: "replicas", Optional: true, DefaultFrom: replicasDefault, Value: &NumberValue{}}
+		fs := NewFlagSet("test", "ILC_INPUT_")
+		fs.Var(&Input{Name: "env", Value: &StringValue{}})
+		fs.Var(replicas)
+		assert.NoError(t, fs.Parse([]string{"-env", "production", "-replicas", "5"}, nil, true))
+		assert.Equal(t, "5", replicas.Value.String())
+	})
+
+	t.Run("invalid default", func(t *testing.T) {
+		fs := NewFlagSet("test", "ILC_INPUT_")
+		fs.Var(&Input{Name: "replicas", Optional: true, DefaultFrom: defaultFunc(func(map[string]any) (string, error) {
+			return "many", nil
+		}), Value: &NumberValue{}})
+		assert.ErrorContains(t, fs.Parse(nil, nil, true), "invalid default for input replicas")
+	})
+}
+
+type ruleFunc func(value Value, values map[string]any) error
+
+func (f ruleFunc) Check(value Value, values map[string]any) error {
+	return f(value, values)
+}
+
+type liveRuleFunc struct {
+	ruleFunc
+}
+
+func (f liveRuleFunc) CheckLive(value Value, values map[string]any) error {
+	return f.ruleFunc(value, values)
+}
+
+func TestFlagSet_Suggestions(t *testing.T) {
+	fs := NewFlagSet("test", "ILC_INPUT_")
+	fs.Var(&Input{Name: "team", Value: &StringValue{Value: "web"}})
+	branch := &Input{Name: "branch", Suggestions: []string{"main", "web-feature"}, Value: &StringValue{}}
+	branch.SuggestionsFrom = optionsFunc(func(values map[string]any) (InputOptions, error) {
+		return InputOptions{{Label: "Main", Value: "main"}, {Label: "Release", Value: fmt.Sprintf("%s-release", values["team"])}}, nil
+	})
+	fs.Var(branch)
+
+	suggestions, err := fs.Suggestions(branch)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"main", "web-feature", "web-release"}, suggestions)
+
+	branch.SuggestionsFrom = optionsFunc(func(values map[string]any) (InputOptions, error) {
+		return nil, errors.New("boom")
+	})
+	suggestions, err = fs.Suggestions(branch)
+	assert.EqualError(t, err, "failed to load suggestions for input branch: boom")
+	assert.Equal(t, []string{"main", "web-feature"}, suggestions)
+
+	assert.NoError(t, fs.Parse([]string{"-team", "api", "-branch", "anything"}, nil, true), "suggestions aren't enforced")
+}
+
+func TestFlagSet_AllowOther(t *testing.T) {
+	newFlagSet := func() (*FlagSet, *Input) {
+		target := &Input{Name: "target", AllowOther: true, Options: InputOptions{{Label: "Main", Value: "main"}, {Label: "Next", Value: "next"}}, Value: &StringValue{Pattern: "^[a-z/-]+$"}}
+		fs := NewFlagSet("test", "ILC_INPUT_")
+		fs.Var(target)
+		return fs, target
+	}
+
+	fs, target := newFlagSet()
+	assert.NoError(t, fs.Parse([]string{"-target", "Next"}, nil, true))
+	assert.Equal(t, "next", target.Value.String(), "labels still resolve to their values")
+
+	fs, target = newFlagSet()
+	assert.NoError(t, fs.Parse([]string{"-target", "fix/login"}, nil, true))
+	assert.Equal(t, "fix/login", target.Value.String())
+
+	fs, _ = newFlagSet()
+	assert.ErrorContains(t, fs.Parse([]string{"-target", "Fix_1"}, nil, true), "invalid value")
+
+	_, target = newFlagSet()
+	assert.NoError(t, target.Check("release"))
+}
+
+func TestFlagSet_Rules(t *testing.T) {
+	afterStart := liveRuleFunc{func(value Value, values map[string]any) error {
+		if value.Get().(float64) <= values["start"].(float64) {
+			return errors.New("must be after start")
+		}
+		return nil
+	}}
+	even := ruleFunc(func(value Value, values map[string]any) error {
+		if int(value.Get().(float64))%2 != 0 {
+			return errors.New("must be even")
+		}
+		return nil
+	})
+	newFlagSet := func() (*FlagSet, *Input) {
+		fs := NewFlagSet("test", "ILC_INPUT_")
+		fs.Var(&Input{Name: "start", Value: &NumberValue{}})
+		end := &Input{Name: "end", Rules: []Rule{afterStart, even}, Value: &NumberValue{}}
+		fs.Var(end)
+		return fs, end
+	}
+
+	t.Run("valid", func(t *testing.T) {
+		fs, end := newFlagSet()
+		assert.NoError(t, fs.Parse([]string{"-end", "4", "-start", "1"}, nil, true))
+		assert.Equal(t, "4", end.Value.String())
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		fs, _ := newFlagSet()
+		assert.EqualError(t, fs.Parse([]string{"-end", "1", "-start", "2"}, nil, true), `invalid value "1" for input end: must be after start`)
+		fs, _ = newFlagSet()
+		assert.EqualError(t, fs.Parse([]string{"-end", "3", "-start", "2"}, nil, true), `invalid value "3" for input end: must be even`)
+	})
+
+	t.Run("live", func(t *testing.T) {
+		fs, end := newFlagSet()
+		assert.NoError(t, fs.Inputs()[0].Value.Set("2"))
+		assert.EqualError(t, fs.ValidateLive(end, "1"), "must be after start")
+		assert.NoError(t, fs.ValidateLive(end, "3"), "only live rules are checked as it's typed")
+		assert.NoError(t, fs.ValidateLive(end, "-"), "incomplete values aren't checked against rules")
+		assert.Error(t, fs.ValidateLive(end, "x"))
+		assert.Equal(t, "0", end.Value.String())
+		assert.EqualError(t, fs.CheckRules(end), "must be after start")
+	})
+}
+
+type transformFunc func(s string, values map[string]any) (string, error)
+
+func (f transformFunc) Transform(s string, values map[string]any) (string, error) {
+	return f(s, values)
+}
+
+func TestFlagSet_Transform(t *testing.T) {
+	lower := transformFunc(func(s string, values map[string]any) (string, error) {
+		return strings.ToLower(s), nil
+	})
+	prefix := transformFunc(func(s string, values map[string]any) (string, error) {
+		return fmt.Sprintf("%s-%s", values["env"], s), nil
+	})
+	newFlagSet := func() (*FlagSet, *Input, *Input) {
+		fs := NewFlagSet("test", "ILC_INPUT_")
+		fs.Var(&Input{Name: "env", Value: &StringValue{}})
+		name := &Input{Name: "name", Transforms: []Transform{lower, prefix}, Value: &StringValue{}}
+		fs.Var(name)
+		tags := &Input{Name: "tags", Optional: true, Transforms: []Transform{lower}, Value: &ListValue{}}
+		fs.Var(tags)
+		return fs, name, tags
+	}
+
+	t.Run("applied in order", func(t *testing.T) {
+		fs, name, tags := newFlagSet()
+		assert.NoError(t, fs.Parse([]string{"-name", "Web", "-env", "prod", "-tags", "A,B"}, nil, true))
+		assert.Equal(t, "prod-web", name.Value.String())
+		assert.Equal(t, []string{"a", "b"}, tags.Value.Get())
+		assert.Equal(t, map[string]string{"ILC_INPUT_ENV": "prod", "ILC_INPUT_NAME": "prod-web", "ILC_INPUT_TAGS": "a,b"}, fs.ToEnvMap())
+		assert.Contains(t, fs.ToArgs(), "--name=prod-web")
+		assert.Equal(t, "prod-web", fs.ToValues()["name"])
+		assert.Equal(t, "Web", name.RawValue().String())
+	})
+
+	t.Run("defaults", func(t *testing.T) {
+		fs, name, _ := newFlagSet()
+		name.Value.(*StringValue).Value = "Api"
+		name.Optional = true
+		assert.NoError(t, fs.Parse([]string{"-env", "dev"}, nil, true))
+		assert.Equal(t, "dev-api", name.Value.String(), "defaults are transformed like values given")
+	})
+
+	t.Run("transformed once", func(t *testing.T) {
+		fs, name, _ := newFlagSet()
+		assert.NoError(t, fs.Parse([]string{"-name", "Web", "-env", "prod"}, nil, true))
+		assert.NoError(t, fs.Transform(name))
+		assert.Equal(t, "prod-web", name.Value.String(), "transforming again starts from the raw value")
+
+		raw := name.RawValue().String()
+		assert.NoError(t, fs.Confirm(name, func() error { return name.Value.Set(raw) }))
+		assert.Equal(t, "prod-web", name.Value.String(), "confirming the raw value again gives the same value")
+
+		assert.NoError(t, fs.Confirm(name, func() error { return name.Value.Set("API") }))
+		assert.Equal(t, "prod-api", name.Value.String())
+		assert.Equal(t, "API", name.RawValue().String())
+	})
+
+	t.Run("recorded values", func(t *testing.T) {
+		fs, name, _ := newFlagSet()
+		name.Recorded = []string{"prod-web"}
+		name.Rules = []Rule{ruleFunc(func(value Value, _ map[string]any) error {
+			if strings.Contains(value.String(), "-") {
+				return errors.New("has a dash")
+			}
+			return nil
+		})}
+		assert.NoError(t, fs.Parse([]string{"-name", "prod-web", "-env", "prod"}, nil, true))
+		assert.Equal(t, "prod-web", name.Value.String(), "recorded values aren't checked or transformed again")
+
+		assert.NoError(t, fs.Confirm(name, func() error { return name.Value.Set("prod-web") }))
+		assert.Equal(t, "prod-web", name.Value.String())
+
+		assert.NoError(t, fs.Confirm(name, func() error { return name.Value.Set("Web") }))
+		assert.Equal(t, "prod-web", name.Value.String(), "other values are transformed")
+
+		region := &Input{Name: "region", Options: InputOptions{{Value: "us"}}, Transforms: []Transform{lower}, Recorded: []string{"US-EAST"}, Value: &StringValue{}}
+		fs = NewFlagSet("test", "ILC_INPUT_")
+		fs.Var(region)
+		assert.NoError(t, fs.Parse([]string{"-region", "US-EAST"}, nil, true))
+		assert.Equal(t, "US-EAST", region.Value.String(), "recorded values aren't matched against the options")
+	})
+
+	t.Run("error", func(t *testing.T) {
+		fs := NewFlagSet("test", "ILC_INPUT_")
+		fs.Var(&Input{Name: "name", Transforms: []Transform{transformFunc(func(string, map[string]any) (string, error) {
+			return "", errors.New("boom")
+		})}, Value: &StringValue{}})
+		assert.EqualError(t, fs.Parse([]string{"-name", "web"}, nil, true), "failed to transform input name: boom")
+	})
+}
+
+func TestFlagSet_ParseOptions_Enforced(t *testing.T) {
+	newFlagSet := func() (*FlagSet, *Input) {
+		month := &Input{Name: "month", Options: InputOptions{{Label: "January", Value: "1"}, {Label: "February", Value: "2"}}, Value: &NumberValue{}}
+		fs := NewFlagSet("test", "ILC_INPUT_")
+		fs.Var(month)
+		return fs, month
+	}
+
+	t.Run("argument label", func(t *testing.T) {
+		fs, month := newFlagSet()
+		assert.NoError(t, fs.Parse([]string{"-month", "February"}, nil, true))
+		assert.Equal(t, "2", month.Value.String())
+	})
+
+	t.Run("invalid argument", func(t *testing.T) {
+		fs, _ := newFlagSet()
+		err := fs.Parse([]string{"-month", "Febuary"}, nil, true)
+		assert.EqualError(t, err, `invalid value "Febuary" for input -month: not one of the options, did you mean "February"?`)
+	})
+
+	t.Run("invalid environment variable", func(t *testing.T) {
+		fs, _ := newFlagSet()
+		err := fs.Parse(nil, map[string]string{"ILC_INPUT_MONTH": "foo"}, true)
+		assert.EqualError(t, err, "invalid environment variable ILC_INPUT_MONTH: not one of the options: 1, 2")
+	})
+
+	t.Run("generated options by label", func(t *testing.T) {
+		branch := &Input{Name: "branch", OptionsFrom: optionsFunc(func(map[string]any) (InputOptions, error) {
+			return InputOptions{{Label: "Main branch", Value: "main"}}, nil
+		}), Value: &StringValue{}}
+		fs := NewFlagSet("test", "ILC_INPUT_")
+		fs.Var(branch)
+		assert.NoError(t, fs.Parse(nil, map[string]string{"ILC_INPUT_BRANCH": "main branch"}, true))
+		assert.Equal(t, "main", branch.Value.String())
+	})
+}
+
+func TestFlagSet_Secret(t *testing.T) {
+	newFlagSet := func() (*FlagSet, *Input) {
+		token := &Input{Name: "token", Short: "t", Position: 1, Secret: true, Value: &StringValue{Pattern: "^tk_"}}
+		fs := NewFlagSet("test", "ILC_INPUT_")
+		fs.Var(&Input{Name: "user", Short: "u", Value: &StringValue{}})
+		fs.Var(&Input{Name: "verbose", Short: "v", Optional: true, Value: &BooleanValue{}})
+		fs.Var(token)
+		return fs, token
+	}
+
+	t.Run("display value", func(t *testing.T) {
+		_, token := newFlagSet()
+		token.Value.Set("tk_123")
+		assert.Equal(t, RedactedValue, token.DisplayValue())
+		assert.Equal(t, `"tk_123"`, Input{}.DisplayArg("tk_123"))
+	})
+
+	t.Run("redact args", func(t *testing.T) {
+		fs, _ := newFlagSet()
+		tests := []struct {
+			args     []string
+			expected []string
+		}{
+			{[]string{"-token", "tk_1", "-user", "bob"}, []string{"-token", RedactedValue, "-user", "bob"}},
+			{[]string{"--token=tk_1"}, []string{"--token=" + RedactedValue}},
+			{[]string{"-vttk_1"}, []string{"-vt" + RedactedValue}},
+			{[]string{"-t=tk_1"}, []string{"-t=" + RedactedValue}},
+			{[]string{"-u", "bob", "tk_1", "--", "tk_2"}, []string{"-u", "bob", RedactedValue, "--", "tk_2"}},
+			{[]string{"-t", "tk_1", "extra"}, []string{"-t", RedactedValue, "extra"}},
+		}
+		for _, tt := range tests {
+			assert.Equal(t, tt.expected, fs.RedactArgs(tt.args), tt.args)
+		}
+	})
+
+	t.Run("left out of args", func(t *testing.T) {
+		fs, _ := newFlagSet()
+		assert.NoError(t, fs.Parse([]string{"-user", "bob", "-token", "tk_1"}, nil, true))
+		assert.Equal(t, []string{"--user=bob", "--no-verbose"}, fs.ToArgs())
+		assert.Equal(t, "tk_1", fs.ToEnvMap()["ILC_INPUT_TOKEN"])
+	})
+
+	t.Run("invalid values are not shown", func(t *testing.T) {
+		fs, _ := newFlagSet()
+		err := fs.Parse([]string{"-user", "bob", "-token", "oops"}, nil, true)
+		assert.EqualError(t, err, "invalid value ****** for input -token: invalid value")
+		err = fs.Parse([]string{"-user", "bob", "oops"}, nil, true)
+		assert.EqualError(t, err, "invalid value ****** for input token: invalid value")
+	})
+}
+
+func TestFlagSet_Multiline(t *testing.T) {
+	dir := t.TempDir()
+	notesPath := filepath.Join(dir, "notes.md")
+	assert.NoError(t, os.WriteFile(notesPath, []byte("# Release\n\n- Fixes\n"), 0o644))
+	newFlagSet := func() (*FlagSet, *Input) {
+		fs := NewFlagSet("test", "ILC_INPUT_")
+		notes := &Input{Name: "notes", Value: &StringValue{Multiline: true}}
+		fs.Var(notes)
+		fs.Var(&Input{Name: "title", Optional: true, Value: &StringValue{}})
+		return fs, notes
+	}
+
+	t.Run("file reference", func(t *testing.T) {
+		fs, notes := newFlagSet()
+		assert.NoError(t, fs.Parse([]string{"-notes", "@" + notesPath, "-title", "@" + notesPath}, nil, true))
+		assert.Equal(t, "# Release\n\n- Fixes", notes.Value.String())
+		assert.Equal(t, "@"+notesPath, fs.Inputs()[1].Value.String())
+		assert.Equal(t, "# Release …", notes.DisplayValue())
+	})
+
+	t.Run("environment variable", func(t *testing.T) {
+		fs, notes := newFlagSet()
+		assert.NoError(t, fs.Parse(nil, map[string]string{"ILC_INPUT_NOTES": "@" + notesPath}, true))
+		assert.Equal(t, "# Release\n\n- Fixes", notes.Value.String())
+	})
+
+	t.Run("escaped", func(t *testing.T) {
+		fs, notes := newFlagSet()
+		assert.NoError(t, fs.Parse([]string{"-notes", "@@here"}, nil, true))
+		assert.Equal(t, "@here", notes.Value.String())
+		assert.Contains(t, fs.ToArgs(), "--notes=@@here")
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		fs, _ := newFlagSet()
+		err := fs.Parse([]string{"-notes", "@" + filepath.Join(dir, "missing.md")}, nil, true)
+		assert.ErrorIs(t, err, os.ErrNotExist)
+	})
+}
+
+func TestInput_Check(t *testing.T) {
+	input := Input{Name: "replicas", Value: &IntegerValue{Value: 2, MinValue: 1, MaxValue: 5}}
+	assert.NoError(t, input.Check("3"))
+	assert.ErrorIs(t, input.Check("9"), ErrInvalidValue)
+	assert.Equal(t, "2", input.Value.String())
+
+	env := Input{Name: "env", Options: InputOptions{{Label: "Staging", Value: "staging"}}, Value: &StringValue{}}
+	assert.NoError(t, env.Check("Staging"))
+	assert.Error(t, env.Check("prod"))
+	assert.Equal(t, "", env.Value.String())
+
+	services := Input{Name: "services", Value: &ListValue{Values: []string{"api"}}}
+	assert.NoError(t, services.Check("web,worker"))
+	assert.Equal(t, []string{"api"}, services.Value.Get())
+}
+
+func TestFlagSet_ParseValuesEnvAndArgs(t *testing.T) {
+	newFlagSet := func() *FlagSet {
+		fs := NewFlagSet("test", "ILC_INPUT_")
+		fs.Var(&Input{Name: "name", Value: &StringValue{}})
+		fs.Var(&Input{Name: "env", Options: InputOptions{{Label: "Production", Value: "prod"}}, Value: &StringValue{}})
+		fs.Var(&Input{Name: "replicas", Value: &IntegerValue{}})
+		fs.Var(&Input{Name: "notes", Value: &StringValue{Multiline: true}})
+		return fs
+	}
+	values := map[string]string{"name": "file", "env": "Production", "replicas": "2", "notes": "@notes.md", "other": "ignored"}
+
+	t.Run("precedence", func(t *testing.T) {
+		fs := newFlagSet()
+		missing, err := fs.ParseValuesEnvAndArgs(values, []string{"-name", "cli"}, map[string]string{"ILC_INPUT_NAME": "env", "ILC_INPUT_REPLICAS": "3"})
+		assert.NoError(t, err)
+		assert.Empty(t, missing)
+		assert.Equal(t, map[string]any{"name": "cli", "env": "prod", "replicas": int64(3), "notes": "@notes.md"}, fs.Values())
+	})
+
+	t.Run("invalid value", func(t *testing.T) {
+		fs := newFlagSet()
+		_, err := fs.ParseValuesEnvAndArgs(map[string]string{"replicas": "two"}, nil, nil)
+		assert.ErrorContains(t, err, `invalid value "two" for input replicas`)
+	})
+
+	t.Run("to values", func(t *testing.T) {
+		fs := newFlagSet()
+		fs.Var(&Input{Name: "token", Secret: true, Value: &StringValue{Value: "tk_123"}})
+		fs.Var(&Input{Name: "services", Value: &ListValue{Values: []string{"api"}}})
+		_, err := fs.ParseValuesEnvAndArgs(values, nil, nil)
+		assert.NoError(t, err)
+		expected := map[string]any{"name": "file", "env": "prod", "replicas": int64(2), "notes": "@notes.md", "services": []string{"api"}}
+		assert.Equal(t, expected, fs.ToValues())
+	})
+}
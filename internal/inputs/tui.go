@@ -2,8 +2,17 @@ package inputs
 
 import (
 	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
 	"strings"
+	"unicode/utf8"
 
+	"github.com/charmbracelet/bubbles/filepicker"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/bubbles/textarea"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
@@ -16,28 +25,333 @@ var (
 	dimStyle      = lipgloss.NewStyle().Foreground(lipgloss.Color("244"))
 	errorStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Bold(true)
 	helpStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("241")).Italic(true)
+	groupStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("32"))
+	descStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
+	disabledStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("238"))
 )
 
 type tuiModel struct {
 	title        string
+	flagSet      *FlagSet
 	inputs       []*Input
 	currentIndex int
 	textInput    textinput.Model
+	textArea     textarea.Model
 	optionsIndex int
+	checked      []bool
+	useDefaults  bool
+	loading      bool
+	spinner      spinner.Model
+	picking      bool
+	picker       filepicker.Model
+	other        bool
 	err          error
 	aborted      bool
 }
 
+// OptionsLoadedMsg is sent once the options of an input have been generated.
+type OptionsLoadedMsg struct {
+	Input   *Input
+	Options InputOptions
+	Err     error
+}
+
+// LoadOptions returns a command that generates the options of the input in
+// the background, evaluated against the values of the FlagSet.
+func LoadOptions(fs *FlagSet, input *Input) tea.Cmd {
+	return func() tea.Msg {
+		var options InputOptions
+		var err error
+		if fs != nil {
+			options, err = fs.GenerateOptions(input)
+		} else {
+			options, err = input.OptionsFrom.Options(map[string]any{})
+		}
+		if err == nil && len(options) == 0 {
+			err = ErrNoOptions
+		}
+		return OptionsLoadedMsg{Input: input, Options: options, Err: err}
+	}
+}
+
+// SuggestionsLoadedMsg is sent once the suggestions of an input have been
+// generated.
+type SuggestionsLoadedMsg struct {
+	Input       *Input
+	Suggestions []string
+}
+
+// LoadSuggestions returns a command that generates the suggestions of the
+// input in the background, evaluated against the values of the FlagSet, or
+// nil when it has none to generate. Suggestions failing to generate are left
+// out, as they are only hints.
+func LoadSuggestions(fs *FlagSet, input *Input) tea.Cmd {
+	if input.SuggestionsFrom == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		if fs == nil {
+			fs = NewFlagSet("", "")
+		}
+		suggestions, _ := fs.Suggestions(input)
+		return SuggestionsLoadedMsg{Input: input, Suggestions: suggestions}
+	}
+}
+
+// DefaultEditor is opened for multiline inputs when $EDITOR is not set.
+const DefaultEditor = "vi"
+
+// EditorClosedMsg is sent once the editor opened for an input exits, with the
+// text it was left with.
+type EditorClosedMsg struct {
+	Input *Input
+	Text  string
+	Err   error
+}
+
+// OpenEditor returns a command editing the text of the input in $EDITOR,
+// suspending the program until the editor exits.
+func OpenEditor(input *Input, text string) tea.Cmd {
+	file, err := os.CreateTemp("", "ilc-*.txt")
+	if err == nil {
+		_, err = file.WriteString(text)
+		file.Close()
+	}
+	if err != nil {
+		return func() tea.Msg {
+			return EditorClosedMsg{Input: input, Err: err}
+		}
+	}
+	editor := os.Getenv("EDITOR")
+	if editor == "" {
+		editor = DefaultEditor
+	}
+	args := append(strings.Fields(editor), file.Name())
+	return tea.ExecProcess(exec.Command(args[0], args[1:]...), func(err error) tea.Msg {
+		defer os.Remove(file.Name())
+		if err != nil {
+			return EditorClosedMsg{Input: input, Err: err}
+		}
+		content, err := os.ReadFile(file.Name())
+		return EditorClosedMsg{Input: input, Text: strings.TrimSuffix(string(content), "\n"), Err: err}
+	})
+}
+
+// NewTextArea returns a text area editing the value of a multiline input.
+func NewTextArea(input *Input) textarea.Model {
+	area := textarea.New()
+	area.ShowLineNumbers = false
+	area.MaxHeight = 0
+	area.SetWidth(72)
+	area.SetHeight(6)
+	area.SetValue(input.RawValue().String())
+	area.Placeholder = Placeholder(input)
+	area.Focus()
+	return area
+}
+
+// OptionIndex returns the index of the option matching the value, or the
+// first option that isn't disabled.
+func OptionIndex(options InputOptions, value string) int {
+	for i, option := range options {
+		if option.Value == value && option.Disabled == "" {
+			return i
+		}
+	}
+	return FirstOption(options)
+}
+
+// FirstOption returns the index of the first option that isn't disabled, or
+// zero when they all are.
+func FirstOption(options InputOptions) int {
+	for i, option := range options {
+		if option.Disabled == "" {
+			return i
+		}
+	}
+	return 0
+}
+
+// NextOption returns the index of the option step places from index, wrapping
+// around and skipping disabled options. The index is kept when no other option
+// can be chosen.
+func NextOption(options InputOptions, index, step int) int {
+	n := len(options)
+	for i := 1; i < n; i++ {
+		next := ((index+i*step)%n + n) % n
+		if options[next].Disabled == "" {
+			return next
+		}
+	}
+	return index
+}
+
+// GroupHeading returns the group of the option at index when it starts a
+// group, which is when it differs from the group of the option before it.
+func GroupHeading(options InputOptions, index int) (string, bool) {
+	group := options[index].Group
+	if group == "" || index > 0 && options[index-1].Group == group {
+		return "", false
+	}
+	return group, true
+}
+
+// OptionDescription returns the description of the option, followed by the
+// reason it's disabled.
+func OptionDescription(option InputOption) string {
+	if option.Disabled == "" {
+		return option.Description
+	}
+	return strings.TrimSpace(fmt.Sprintf("%s (disabled: %s)", option.Description, option.Disabled))
+}
+
+// LabelWidth returns the width of the longest label of the options, to align
+// their descriptions.
+func LabelWidth(options InputOptions) int {
+	width := 0
+	for _, option := range options {
+		width = max(width, utf8.RuneCountInString(option.Label))
+	}
+	return width
+}
+
+// OtherLabel labels the entry after the options that switches to typing a value.
+const OtherLabel = "Other…"
+
+// ChoiceOptions returns the options of the input to choose from, followed by
+// an entry labelled OtherLabel when it allows other values.
+func ChoiceOptions(input *Input) InputOptions {
+	if !input.AllowOther {
+		return input.Options
+	}
+	return append(slices.Clip(input.Options), InputOption{Label: OtherLabel})
+}
+
+// IsOther reports whether the option chosen at index is the entry to type
+// another value.
+func IsOther(input *Input, index int) bool {
+	return input.AllowOther && index == len(input.Options)
+}
+
+// OtherValue returns the value to start typing another value from, which is
+// the value of the input unless it's one of the options.
+func OtherValue(input *Input) string {
+	value := input.RawValue().String()
+	if _, found := input.Options.Lookup(value); found {
+		return ""
+	}
+	return value
+}
+
+// IsMultiSelect reports whether several of the input's options can be chosen.
+func IsMultiSelect(input *Input) bool {
+	_, isList := input.Value.(*ListValue)
+	return isList && input.Selectable()
+}
+
+// CheckedOptions returns which of the options are items of a list value,
+// leaving disabled options unchecked.
+func CheckedOptions(options InputOptions, value Value) []bool {
+	checked := make([]bool, len(options))
+	if list, ok := value.(*ListValue); ok {
+		for i, option := range options {
+			checked[i] = option.Disabled == "" && slices.Contains(list.Values, option.Value)
+		}
+	}
+	return checked
+}
+
+// CheckedValues returns the values of the checked options.
+func CheckedValues(options InputOptions, checked []bool) []string {
+	values := []string{}
+	for i, option := range options {
+		if i < len(checked) && checked[i] {
+			values = append(values, option.Value)
+		}
+	}
+	return values
+}
+
+// PathPicker returns a file picker for the input when it is a path chosen by
+// browsing, starting from the directory of its current value.
+func PathPicker(input *Input) (filepicker.Model, bool) {
+	path, ok := input.Value.(*PathValue)
+	if !ok || !path.Picker || input.Selectable() {
+		return filepicker.Model{}, false
+	}
+	picker := filepicker.New()
+	picker.CurrentDirectory = path.Abs(".")
+	if current := path.Abs(path.Value); current != "" && isDir(filepath.Dir(current)) {
+		picker.CurrentDirectory = filepath.Dir(current)
+	}
+	picker.FileAllowed = path.Kind != PathKindDir
+	picker.DirAllowed = path.Kind != PathKindFile
+	for _, ext := range path.Extensions {
+		picker.AllowedTypes = append(picker.AllowedTypes, "."+strings.TrimPrefix(ext, "."))
+	}
+	picker.AutoHeight = false
+	picker.SetHeight(10)
+	// Esc is left to the prompt
+	picker.KeyMap.Back = key.NewBinding(key.WithKeys("h", "backspace", "left"), key.WithHelp("h", "back"))
+	return picker, true
+}
+
+// Placeholder returns the text shown while the input's text is empty, which is
+// its value, or a hint of what it expects when it has none.
+func Placeholder(input *Input) string {
+	if value := input.RawValue().String(); value != "" {
+		return value
+	}
+	if hinter, ok := input.Value.(Hinter); ok {
+		return hinter.Hint()
+	}
+	return ""
+}
+
+// Complete sets the suggestions of the text input to the completions of its
+// value, when the input's value can be completed.
+func Complete(textInput *textinput.Model, input *Input) {
+	if completer, ok := input.Value.(Completer); ok {
+		textInput.ShowSuggestions = true
+		textInput.SetSuggestions(completer.Complete(textInput.Value()))
+	}
+}
+
+// Suggest offers the suggestions as completions of the text typed for the
+// input, unless its value completes itself or is secret.
+func Suggest(textInput *textinput.Model, input *Input, suggestions []string) {
+	if _, ok := input.Value.(Completer); ok || input.Secret || len(suggestions) == 0 {
+		return
+	}
+	textInput.ShowSuggestions = true
+	textInput.SetSuggestions(suggestions)
+}
+
 func (m *tuiModel) initCurrentInput() {
 	m.err = nil
+	m.loading = false
+	m.picking = false
+	m.other = false
 	current := m.inputs[m.currentIndex]
-	if !current.Selectable() && !m.isBooleanInput(current) {
-		m.textInput = textinput.New()
-		m.textInput.SetValue(current.Value.String())
-		m.textInput.Placeholder = current.Value.String()
-		m.textInput.Focus()
-	} else {
+	if current.OptionsFrom != nil {
+		m.loading = true
 		m.optionsIndex = 0
+		return
+	}
+	if picker, ok := PathPicker(current); ok {
+		m.picker = picker
+		m.picking = true
+		return
+	}
+	if current.Multiline() {
+		m.textArea = NewTextArea(current)
+		return
+	}
+	if !m.choosing(current) {
+		m.initTextInput(current, current.RawValue().String())
+	} else {
+		m.optionsIndex = FirstOption(ChoiceOptions(current))
+		m.checked = CheckedOptions(current.Options, current.Value)
 		if m.isBooleanInput(current) {
 			opts := m.getBooleanOptions(current)
 			targetVal := "false"
@@ -55,55 +369,180 @@ func (m *tuiModel) initCurrentInput() {
 	}
 }
 
+// initTextInput starts typing the value of the input from value.
+func (m *tuiModel) initTextInput(current *Input, value string) {
+	m.textInput = textinput.New()
+	m.textInput.SetValue(value)
+	m.textInput.Placeholder = Placeholder(current)
+	if current.Secret {
+		m.textInput.EchoMode = textinput.EchoPassword
+		m.textInput.EchoCharacter = '•'
+		m.textInput.Placeholder = ""
+	}
+	Complete(&m.textInput, current)
+	Suggest(&m.textInput, current, current.Suggestions)
+	m.textInput.Focus()
+}
+
 func (m *tuiModel) Init() tea.Cmd {
-	return textinput.Blink
+	return tea.Batch(textinput.Blink, m.startInput())
+}
+
+// startInput returns the command the current input starts with, reading the
+// directory of its picker or loading its options or suggestions.
+func (m *tuiModel) startInput() tea.Cmd {
+	if m.picking {
+		return m.picker.Init()
+	}
+	return tea.Batch(m.loadOptions(), m.loadSuggestions())
+}
+
+// loadOptions returns the command loading the options of the current input,
+// if it is waiting on them.
+func (m *tuiModel) loadOptions() tea.Cmd {
+	if !m.loading {
+		return nil
+	}
+	m.spinner = spinner.New(spinner.WithSpinner(spinner.Dot))
+	return tea.Batch(m.spinner.Tick, LoadOptions(m.flagSet, m.inputs[m.currentIndex]))
+}
+
+// loadSuggestions returns the command loading the suggestions of the current
+// input, if it is typed.
+func (m *tuiModel) loadSuggestions() tea.Cmd {
+	if m.loading || m.currentIndex < 0 || m.currentIndex >= len(m.inputs) {
+		return nil
+	}
+	current := m.inputs[m.currentIndex]
+	if current.Multiline() || m.choosing(current) {
+		return nil
+	}
+	return LoadSuggestions(m.flagSet, current)
 }
 
 func (m *tuiModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmd tea.Cmd
 
 	switch msg := msg.(type) {
+	case OptionsLoadedMsg:
+		current := m.inputs[m.currentIndex]
+		if msg.Input != current || !m.loading {
+			return m, nil
+		}
+		m.loading = false
+		m.err = msg.Err
+		current.Options = msg.Options
+		m.optionsIndex = OptionIndex(msg.Options, current.Value.String())
+		m.checked = CheckedOptions(msg.Options, current.Value)
+		return m, nil
+
+	case SuggestionsLoadedMsg:
+		if current := m.inputs[m.currentIndex]; msg.Input == current {
+			Suggest(&m.textInput, current, msg.Suggestions)
+		}
+		return m, nil
+
+	case spinner.TickMsg:
+		if !m.loading {
+			return m, nil
+		}
+		m.spinner, cmd = m.spinner.Update(msg)
+		return m, cmd
+
+	case EditorClosedMsg:
+		if msg.Input != m.inputs[m.currentIndex] {
+			return m, nil
+		}
+		m.err = msg.Err
+		if msg.Err == nil {
+			m.textArea.SetValue(msg.Text)
+		}
+		return m, nil
+
 	case tea.KeyMsg:
+		if m.picking && msg.Type != tea.KeyCtrlC && msg.Type != tea.KeyEsc && msg.Type != tea.KeyCtrlD {
+			return m.updatePicker(msg)
+		}
+		if m.inputs[m.currentIndex].Multiline() && msg.Type != tea.KeyCtrlC && msg.Type != tea.KeyEsc && msg.Type != tea.KeyCtrlD {
+			return m.updateTextArea(msg)
+		}
 		switch msg.Type {
-		case tea.KeyCtrlC, tea.KeyEsc:
+		case tea.KeyEsc:
+			if m.other {
+				m.other = false
+				m.err = nil
+				return m, nil
+			}
+			m.aborted = true
+			return m, tea.Quit
+
+		case tea.KeyCtrlC:
 			m.aborted = true
 			return m, tea.Quit
 
 		case tea.KeyEnter:
 			current := m.inputs[m.currentIndex]
+			if m.loading || (m.choosing(current) && len(m.getBooleanOptions(current)) == 0) {
+				return m, nil
+			}
+			if m.choosing(current) && IsOther(current, m.optionsIndex) {
+				m.other = true
+				m.err = nil
+				m.initTextInput(current, OtherValue(current))
+				return m, m.loadSuggestions()
+			}
+			if list, ok := current.Value.(*ListValue); ok && IsMultiSelect(current) {
+				values := CheckedValues(current.Options, m.checked)
+				if err := m.confirmValue(current, func() error { return list.SetItems(values) }); err != nil {
+					m.err = err
+					return m, nil
+				}
+				m.err = nil
+				return m.advance(m.currentIndex + 1)
+			}
 			var val string
-			if current.Selectable() || m.isBooleanInput(current) {
-				opts := m.getBooleanOptions(current)
-				val = opts[m.optionsIndex].Value
+			if m.choosing(current) {
+				option := m.getBooleanOptions(current)[m.optionsIndex]
+				if option.Disabled != "" {
+					return m, nil
+				}
+				val = option.Value
 			} else {
 				val = m.textInput.Value()
 				if val == "" {
-					val = current.Value.String()
+					val = current.RawValue().String()
 				}
 			}
 
-			if err := current.Value.Set(val); err != nil {
+			if err := m.confirmValue(current, func() error { return current.Value.Set(val) }); err != nil {
 				m.err = err
 				return m, nil
 			}
 
 			m.err = nil
-			if m.currentIndex < len(m.inputs)-1 {
-				m.currentIndex++
-				m.initCurrentInput()
+			return m.advance(m.currentIndex + 1)
+
+		case tea.KeyCtrlD:
+			m.useDefaults = true
+			return m.advance(m.currentIndex)
+
+		case tea.KeySpace:
+			if current := m.inputs[m.currentIndex]; IsMultiSelect(current) {
+				if !m.loading && m.optionsIndex < len(m.checked) && current.Options[m.optionsIndex].Disabled == "" {
+					m.checked[m.optionsIndex] = !m.checked[m.optionsIndex]
+					m.err = nil
+				}
 				return m, nil
 			}
-			return m, tea.Quit
 
 		case tea.KeyUp:
 			current := m.inputs[m.currentIndex]
-			if current.Selectable() || m.isBooleanInput(current) {
+			if m.choosing(current) {
 				opts := m.getBooleanOptions(current)
-				if m.optionsIndex > 0 {
-					m.optionsIndex--
-				} else {
-					m.optionsIndex = len(opts) - 1
+				if len(opts) == 0 {
+					return m, nil
 				}
+				m.optionsIndex = NextOption(opts, m.optionsIndex, -1)
 			} else if adjustable, ok := current.Value.(AdjustableValue); ok {
 				newStr, err := adjustable.Adjust(m.textInput.Value(), 1)
 				m.textInput.SetValue(newStr)
@@ -113,13 +552,12 @@ func (m *tuiModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		case tea.KeyDown:
 			current := m.inputs[m.currentIndex]
-			if current.Selectable() || m.isBooleanInput(current) {
+			if m.choosing(current) {
 				opts := m.getBooleanOptions(current)
-				if m.optionsIndex < len(opts)-1 {
-					m.optionsIndex++
-				} else {
-					m.optionsIndex = 0
+				if len(opts) == 0 {
+					return m, nil
 				}
+				m.optionsIndex = NextOption(opts, m.optionsIndex, 1)
 			} else if adjustable, ok := current.Value.(AdjustableValue); ok {
 				newStr, err := adjustable.Adjust(m.textInput.Value(), -1)
 				m.textInput.SetValue(newStr)
@@ -129,11 +567,57 @@ func (m *tuiModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 	}
 
+	if m.picking {
+		return m.updatePicker(msg)
+	}
+
 	current := m.inputs[m.currentIndex]
-	if !current.Selectable() && !m.isBooleanInput(current) {
+	if current.Multiline() {
+		m.textArea, cmd = m.textArea.Update(msg)
+	} else if !m.choosing(current) {
 		m.textInput, cmd = m.textInput.Update(msg)
+		Complete(&m.textInput, current)
+	}
+
+	return m, cmd
+}
+
+// updatePicker passes the message to the file picker, setting the input to
+// the path once one is selected.
+func (m *tuiModel) updatePicker(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+	m.picker.Path = ""
+	m.picker, cmd = m.picker.Update(msg)
+	if m.picker.Path == "" {
+		return m, cmd
+	}
+	current := m.inputs[m.currentIndex]
+	if err := m.confirmValue(current, func() error { return current.Value.Set(m.picker.Path) }); err != nil {
+		m.err = err
+		return m, cmd
 	}
+	m.err = nil
+	return m.advance(m.currentIndex + 1)
+}
+
+// updateTextArea passes the key to the text area of a multiline input, which
+// is confirmed with Ctrl+S so Enter can start a new line.
+func (m *tuiModel) updateTextArea(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	current := m.inputs[m.currentIndex]
+	switch msg.Type {
+	case tea.KeyCtrlS:
+		if err := m.confirmValue(current, func() error { return current.Value.Set(m.textArea.Value()) }); err != nil {
+			m.err = err
+			return m, nil
+		}
+		m.err = nil
+		return m.advance(m.currentIndex + 1)
 
+	case tea.KeyCtrlE:
+		return m, OpenEditor(current, m.textArea.Value())
+	}
+	var cmd tea.Cmd
+	m.textArea, cmd = m.textArea.Update(msg)
 	return m, cmd
 }
 
@@ -152,7 +636,7 @@ func (m *tuiModel) View() string {
 	progress := fmt.Sprintf("[%d/%d]", m.currentIndex+1, len(m.inputs))
 	prompt := current.Description
 	if prompt == "" {
-		if current.Selectable() || m.isBooleanInput(current) {
+		if m.choosing(current) {
 			prompt = fmt.Sprintf("Choose a %s", current.Name)
 		} else {
 			prompt = fmt.Sprintf("Please specify a %s", current.Name)
@@ -162,14 +646,34 @@ func (m *tuiModel) View() string {
 	sb.WriteString(fmt.Sprintf("%s %s\n", progressStyle.Render(progress), prompt))
 
 	// Render specific control
-	if current.Selectable() || m.isBooleanInput(current) {
+	if m.loading {
+		sb.WriteString("\n  " + m.spinner.View() + dimStyle.Render("Loading options…") + "\n")
+	} else if m.picking {
+		sb.WriteString("\n  " + dimStyle.Render(m.picker.CurrentDirectory) + "\n" + m.picker.View() + "\n")
+	} else if current.Multiline() {
+		sb.WriteString("\n  " + strings.ReplaceAll(m.textArea.View(), "\n", "\n  ") + "\n")
+	} else if m.choosing(current) {
 		sb.WriteString("\n")
 		opts := m.getBooleanOptions(current)
+		width := LabelWidth(opts)
 		for i, option := range opts {
-			if i == m.optionsIndex {
-				sb.WriteString(fmt.Sprintf("  ❯ %s\n", accentStyle.Render(option.Label)))
+			if group, ok := GroupHeading(opts, i); ok {
+				sb.WriteString(fmt.Sprintf("  %s\n", groupStyle.Render(group)))
+			}
+			label := option.Label
+			if IsMultiSelect(current) {
+				label = checkbox(i < len(m.checked) && m.checked[i]) + label
+			}
+			var desc string
+			if s := OptionDescription(option); s != "" {
+				desc = strings.Repeat(" ", width-utf8.RuneCountInString(option.Label)+3) + s
+			}
+			if option.Disabled != "" {
+				sb.WriteString(fmt.Sprintf("    %s\n", disabledStyle.Render(label+desc)))
+			} else if i == m.optionsIndex {
+				sb.WriteString(fmt.Sprintf("  ❯ %s%s\n", accentStyle.Render(label), dimStyle.Render(desc)))
 			} else {
-				sb.WriteString(fmt.Sprintf("    %s\n", dimStyle.Render(option.Label)))
+				sb.WriteString(fmt.Sprintf("    %s%s\n", dimStyle.Render(label), descStyle.Render(desc)))
 			}
 		}
 	} else {
@@ -186,12 +690,111 @@ func (m *tuiModel) View() string {
 	if _, isAdjustable := current.Value.(AdjustableValue); isAdjustable {
 		helpParts = append(helpParts, "[Up/Down] +/-")
 	}
-	helpParts = append(helpParts, "[Enter] Confirm", "[Ctrl+C / Esc] Abort")
+	if IsMultiSelect(current) {
+		helpParts = append(helpParts, "[Space] Toggle")
+	}
+	if m.picking {
+		helpParts = append(helpParts, "[Left/Right] Browse", "[Enter] Select")
+	} else if current.Multiline() {
+		helpParts = append(helpParts, "[Ctrl+E] Editor", "[Ctrl+S] Confirm")
+	} else {
+		helpParts = append(helpParts, "[Enter] Confirm")
+	}
+	if m.hasOptionalInputs() {
+		helpParts = append(helpParts, "[Ctrl+D] Use defaults")
+	}
+	if m.other {
+		helpParts = append(helpParts, "[Esc] Options", "[Ctrl+C] Abort")
+	} else {
+		helpParts = append(helpParts, "[Ctrl+C / Esc] Abort")
+	}
 	sb.WriteString("\n" + helpStyle.Render("  "+strings.Join(helpParts, "  •  ")) + "\n")
 
 	return sb.String()
 }
 
+// advance moves to the next input from start that needs prompting, skipping
+// optional inputs once defaults have been accepted, and quits when none remain.
+// Templated defaults are rendered from the answers so far as inputs are reached.
+func (m *tuiModel) advance(start int) (tea.Model, tea.Cmd) {
+	for i := start; i < len(m.inputs); i++ {
+		input := m.inputs[i]
+		if !m.applies(input) {
+			continue
+		}
+		if m.useDefaults && !input.Required() {
+			_ = m.applyDefault(input)
+			continue
+		}
+		if i != m.currentIndex {
+			m.currentIndex = i
+			err := m.applyDefault(input)
+			m.initCurrentInput()
+			if err != nil {
+				m.err = err
+			}
+			return m, m.startInput()
+		}
+		return m, nil
+	}
+	return m, tea.Quit
+}
+
+// applyDefault renders the default of the input and transforms it, like the
+// defaults of inputs not prompted for.
+func (m *tuiModel) applyDefault(input *Input) error {
+	if m.flagSet == nil {
+		return nil
+	}
+	if err := m.flagSet.ApplyDefault(input); err != nil {
+		return err
+	}
+	return m.flagSet.Transform(input)
+}
+
+// confirmValue sets the value of the input with set, then checks it against
+// its rules and applies its transforms. The input keeps its previous value when
+// any of them fails.
+func (m *tuiModel) confirmValue(input *Input, set func() error) error {
+	if m.flagSet == nil {
+		return set()
+	}
+	return m.flagSet.Confirm(input, set)
+}
+
+// applies reports whether the input's condition holds for the values given so
+// far. Conditions that fail to evaluate skip the input.
+func (m *tuiModel) applies(input *Input) bool {
+	if m.flagSet == nil {
+		return true
+	}
+	applies, _ := m.flagSet.Applies(input)
+	return applies
+}
+
+func (m *tuiModel) hasOptionalInputs() bool {
+	for _, input := range m.inputs[m.currentIndex:] {
+		if !input.Required() {
+			return true
+		}
+	}
+	return false
+}
+
+// checkbox returns the marker of a multi-select option
+func checkbox(checked bool) string {
+	if checked {
+		return "◉ "
+	}
+	return "○ "
+}
+
+// choosing reports whether the value of the input is chosen from a list,
+// rather than typed.
+func (m *tuiModel) choosing(current *Input) bool {
+	return (current.Selectable() || m.isBooleanInput(current)) && !m.other
+}
+
 func (m *tuiModel) isBooleanInput(current *Input) bool {
 	_, isBool := current.Value.(*BooleanValue)
 	return isBool
@@ -199,7 +802,7 @@ func (m *tuiModel) isBooleanInput(current *Input) bool {
 
 func (m *tuiModel) getBooleanOptions(current *Input) []InputOption {
 	if current.Selectable() {
-		return current.Options
+		return ChoiceOptions(current)
 	}
 	return []InputOption{
 		{Label: "true", Value: "true"},
@@ -211,15 +814,21 @@ type Prompter interface {
 	Prompt(title string, missing []*Input) error
 }
 
-type TuiPrompter struct{}
+type TuiPrompter struct {
+	flagSet *FlagSet
+}
 
 func (tp TuiPrompter) Prompt(title string, missing []*Input) error {
 	m := tuiModel{
 		title:        title,
+		flagSet:      tp.flagSet,
 		inputs:       missing,
-		currentIndex: 0,
+		currentIndex: -1,
+	}
+	if m.advance(0); m.currentIndex < 0 {
+		// None of the inputs apply
+		return nil
 	}
-	m.initCurrentInput()
 
 	p := tea.NewProgram(&m)
 	if _, err := p.Run(); err != nil {
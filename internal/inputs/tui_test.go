@@ -1,8 +1,13 @@
 package inputs
 
 import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
+	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/stretchr/testify/assert"
 )
@@ -269,3 +274,423 @@ func TestTuiModel_NonSelectableKeystrokes(t *testing.T) {
 }
 
 
+
+func TestTuiModel_UseDefaults(t *testing.T) {
+	optional1 := &Input{Name: "first", Optional: true, Value: &StringValue{Value: "A"}}
+	required := &Input{Name: "second", Value: &StringValue{}}
+	optional2 := &Input{Name: "third", Optional: true, Value: &StringValue{Value: "C"}}
+	m := &tuiModel{
+		inputs: []*Input{optional1, required, optional2},
+	}
+	m.initCurrentInput()
+	assert.Contains(t, m.View(), "[Ctrl+D] Use defaults")
+
+	// Accepting defaults skips to the next required input
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyCtrlD})
+	assert.Nil(t, cmd)
+	assert.Equal(t, 1, m.currentIndex)
+
+	// Confirming the required input skips the remaining optional inputs
+	m.textInput.SetValue("B")
+	_, cmd = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	assert.NotNil(t, cmd)
+	assert.Equal(t, "A", optional1.Value.String())
+	assert.Equal(t, "B", required.Value.String())
+	assert.Equal(t, "C", optional2.Value.String())
+}
+
+func TestTuiModel_ConditionalInputs(t *testing.T) {
+	source := &Input{Name: "source", Options: InputOptions{{Label: "local", Value: "local"}, {Label: "registry", Value: "registry"}}, Value: &StringValue{}}
+	tag := &Input{Name: "tag", When: whenEquals("source", "registry"), Value: &StringValue{Value: "latest"}}
+	name := &Input{Name: "name", Value: &StringValue{Value: "app"}}
+	fs := NewFlagSet("test", "ILC_INPUT_")
+	fs.Var(source)
+	fs.Var(tag)
+	fs.Var(name)
+	m := &tuiModel{
+		flagSet:      fs,
+		inputs:       fs.Inputs(),
+		currentIndex: -1,
+	}
+	m.advance(0)
+	assert.Equal(t, 0, m.currentIndex)
+
+	// Choosing local skips the tag input
+	_, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	assert.Equal(t, 2, m.currentIndex)
+}
+
+func TestTuiModel_OptionsFrom(t *testing.T) {
+	branch := &Input{Name: "branch", OptionsFrom: optionsFunc(func(map[string]any) (InputOptions, error) {
+		return InputOptions{{Label: "main", Value: "main"}, {Label: "feature", Value: "feature"}}, nil
+	}), Value: &StringValue{Value: "feature"}}
+	fs := NewFlagSet("test", "ILC_INPUT_")
+	fs.Var(branch)
+	m := &tuiModel{
+		flagSet:      fs,
+		inputs:       fs.Inputs(),
+		currentIndex: -1,
+	}
+	_, cmd := m.advance(0)
+	assert.NotNil(t, cmd)
+	assert.True(t, m.loading)
+	assert.Contains(t, m.View(), "Loading options…")
+
+	// Enter is ignored while loading
+	_, cmd = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	assert.Nil(t, cmd)
+	assert.Equal(t, 0, m.currentIndex)
+
+	msg := LoadOptions(fs, branch)()
+	_, _ = m.Update(msg)
+	assert.False(t, m.loading)
+	assert.Equal(t, 1, m.optionsIndex)
+	assert.Contains(t, m.View(), "main")
+
+	_, _ = m.Update(tea.KeyMsg{Type: tea.KeyUp})
+	_, cmd = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	assert.NotNil(t, cmd)
+	assert.Equal(t, "main", branch.Value.String())
+}
+
+func TestTuiModel_OptionsFromError(t *testing.T) {
+	branch := &Input{Name: "branch", OptionsFrom: optionsFunc(func(map[string]any) (InputOptions, error) {
+		return nil, nil
+	}), Value: &StringValue{}}
+	m := &tuiModel{
+		inputs:       []*Input{branch},
+		currentIndex: -1,
+	}
+	m.advance(0)
+	_, _ = m.Update(LoadOptions(nil, branch)())
+	assert.ErrorIs(t, m.err, ErrNoOptions)
+
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	assert.Nil(t, cmd)
+}
+
+func TestTuiModel_MultiSelect(t *testing.T) {
+	list := &ListValue{Values: []string{"web"}, MinItems: 1}
+	svc := &Input{Name: "svc", Options: InputOptions{{Label: "API", Value: "api"}, {Label: "Web", Value: "web"}}, Value: list}
+	m := &tuiModel{
+		inputs:       []*Input{svc},
+		currentIndex: -1,
+	}
+	m.advance(0)
+	assert.Equal(t, []bool{false, true}, m.checked)
+	view := m.View()
+	assert.Contains(t, view, "○ API")
+	assert.Contains(t, view, "◉ Web")
+	assert.Contains(t, view, "[Space] Toggle")
+
+	// Unchecking everything is below the minimum
+	_, _ = m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	_, _ = m.Update(tea.KeyMsg{Type: tea.KeySpace})
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	assert.Nil(t, cmd)
+	assert.Error(t, m.err)
+
+	_, _ = m.Update(tea.KeyMsg{Type: tea.KeyUp})
+	_, _ = m.Update(tea.KeyMsg{Type: tea.KeySpace})
+	_, _ = m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	_, _ = m.Update(tea.KeyMsg{Type: tea.KeySpace})
+	_, cmd = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	assert.NotNil(t, cmd)
+	assert.Equal(t, []string{"api", "web"}, list.Values)
+}
+
+func TestTuiModel_OptionEntries(t *testing.T) {
+	list := &ListValue{Values: []string{"api", "db"}}
+	svc := &Input{Name: "svc", Value: list, Options: InputOptions{
+		{Label: "Database", Value: "db", Group: "Storage", Disabled: "Under maintenance"},
+		{Label: "API", Value: "api", Group: "Services", Description: "Public endpoints"},
+		{Label: "Web", Value: "web", Group: "Services"},
+	}}
+	m := &tuiModel{
+		inputs:       []*Input{svc},
+		currentIndex: -1,
+	}
+	m.advance(0)
+	assert.Equal(t, []bool{false, true, false}, m.checked, "disabled options aren't checked")
+	assert.Equal(t, 1, m.optionsIndex)
+	view := m.View()
+	assert.Equal(t, 1, strings.Count(view, "Services"), "groups head their options once")
+	assert.Contains(t, view, "Storage")
+	assert.Contains(t, view, "◉ API        Public endpoints")
+	assert.Contains(t, view, "○ Database   (disabled: Under maintenance)")
+
+	_, _ = m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	_, _ = m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	assert.Equal(t, 1, m.optionsIndex, "disabled options are skipped")
+	_, _ = m.Update(tea.KeyMsg{Type: tea.KeySpace})
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	assert.NotNil(t, cmd)
+	assert.Equal(t, []string{}, list.Values)
+}
+
+func TestNextOption(t *testing.T) {
+	options := InputOptions{{Value: "a"}, {Value: "b", Disabled: "No"}, {Value: "c"}}
+	assert.Equal(t, 2, NextOption(options, 0, 1))
+	assert.Equal(t, 0, NextOption(options, 2, 1))
+	assert.Equal(t, 2, NextOption(options, 0, -1))
+	assert.Equal(t, 0, NextOption(InputOptions{{Value: "a"}, {Value: "b", Disabled: "No"}}, 0, 1), "stays when the rest are disabled")
+	assert.Equal(t, 2, FirstOption(InputOptions{{Disabled: "No"}, {Disabled: "No"}, {Value: "c"}}))
+	assert.Equal(t, 0, OptionIndex(options, "b"), "disabled values aren't chosen")
+}
+
+func TestGroupHeading(t *testing.T) {
+	options := InputOptions{{Value: "a"}, {Value: "b", Group: "B"}, {Value: "c", Group: "B"}, {Value: "d", Group: "D"}}
+	var headings []string
+	for i := range options {
+		if group, ok := GroupHeading(options, i); ok {
+			headings = append(headings, group)
+		}
+	}
+	assert.Equal(t, []string{"B", "D"}, headings)
+}
+
+func TestTuiModel_Secret(t *testing.T) {
+	token := &Input{Name: "token", Secret: true, Value: &StringValue{Value: "tk_123"}}
+	m := &tuiModel{
+		inputs:       []*Input{token},
+		currentIndex: -1,
+	}
+	m.advance(0)
+	assert.Equal(t, textinput.EchoPassword, m.textInput.EchoMode)
+	assert.NotContains(t, m.View(), "tk_123")
+}
+
+func TestTuiModel_PathPicker(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.Mkdir(filepath.Join(dir, "sub"), 0o755))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "a.txt"), nil, 0o644))
+	path := &PathValue{Base: dir, Kind: PathKindFile, Picker: true}
+	input := &Input{Name: "file", Value: path}
+	m := &tuiModel{
+		inputs:       []*Input{input},
+		currentIndex: -1,
+	}
+	_, cmd := m.advance(0)
+	assert.True(t, m.picking)
+	assert.Equal(t, dir, m.picker.CurrentDirectory)
+	assert.NotNil(t, cmd)
+	_, _ = m.Update(cmd())
+	view := m.View()
+	assert.Contains(t, view, "a.txt")
+	assert.Contains(t, view, "[Enter] Select")
+
+	_, _ = m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	_, cmd = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	assert.NotNil(t, cmd)
+	assert.Equal(t, filepath.Join(dir, "a.txt"), path.Value)
+}
+
+func TestTuiModel_PathCompletion(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "config.yml"), nil, 0o644))
+	input := &Input{Name: "file", Value: &PathValue{Base: dir}}
+	m := &tuiModel{
+		inputs:       []*Input{input},
+		currentIndex: -1,
+	}
+	m.advance(0)
+	_, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("co")})
+	assert.Contains(t, m.View(), "nfig.yml")
+	_, _ = m.Update(tea.KeyMsg{Type: tea.KeyTab})
+	assert.Equal(t, "config.yml", m.textInput.Value())
+}
+
+func TestTuiModel_Multiline(t *testing.T) {
+	notes := &Input{Name: "notes", Value: &StringValue{Value: "First", Multiline: true}}
+	m := &tuiModel{
+		inputs:       []*Input{notes},
+		currentIndex: -1,
+	}
+	m.advance(0)
+	assert.Equal(t, "First", m.textArea.Value())
+	assert.Contains(t, m.View(), "[Ctrl+S] Confirm")
+
+	// Enter starts a new line rather than confirming
+	_, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	_, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("Second")})
+	assert.Equal(t, "First\nSecond", m.textArea.Value())
+
+	// The editor's text replaces the text area's
+	assert.NotNil(t, OpenEditor(notes, m.textArea.Value()))
+	_, _ = m.Update(EditorClosedMsg{Input: notes, Text: "Edited\ntext"})
+	assert.Equal(t, "Edited\ntext", m.textArea.Value())
+
+	assert.Equal(t, "First", notes.Value.String())
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyCtrlS})
+	assert.NotNil(t, cmd)
+	assert.Equal(t, "Edited\ntext", notes.Value.String())
+}
+
+func TestTuiModel_Rules(t *testing.T) {
+	name := &Input{Name: "name", Value: &StringValue{}, Rules: []Rule{ruleFunc(func(value Value, values map[string]any) error {
+		if value.String() == "admin" {
+			return errors.New("is reserved")
+		}
+		return nil
+	})}}
+	fs := NewFlagSet("test", "ILC_INPUT_")
+	fs.Var(name)
+	m := &tuiModel{
+		inputs:       []*Input{name},
+		flagSet:      fs,
+		currentIndex: -1,
+	}
+	m.advance(0)
+	_, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("admin")})
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	assert.Nil(t, cmd)
+	assert.EqualError(t, m.err, "is reserved")
+	assert.Contains(t, m.View(), "is reserved")
+}
+
+func TestTuiModel_RejectedValueNotKept(t *testing.T) {
+	name := &Input{Name: "name", Optional: true, Value: &StringValue{Value: "guest"}, Rules: []Rule{ruleFunc(func(value Value, values map[string]any) error {
+		if value.String() == "admin" {
+			return errors.New("is reserved")
+		}
+		return nil
+	})}}
+	fs := NewFlagSet("test", "ILC_INPUT_")
+	fs.Var(name)
+	m := &tuiModel{
+		inputs:       []*Input{name},
+		flagSet:      fs,
+		currentIndex: -1,
+	}
+	m.advance(0)
+	m.textInput.SetValue("admin")
+	_, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	assert.EqualError(t, m.err, "is reserved")
+	assert.Equal(t, "guest", name.Value.String(), "rejected values aren't kept")
+
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyCtrlD})
+	assert.NotNil(t, cmd)
+	assert.Equal(t, "guest", name.Value.String())
+}
+
+func TestTuiModel_Suggestions(t *testing.T) {
+	branch := &Input{Name: "branch", Suggestions: []string{"main", "develop"}, Value: &StringValue{}}
+	branch.SuggestionsFrom = optionsFunc(func(values map[string]any) (InputOptions, error) {
+		return InputOptions{{Label: "release", Value: "release"}}, nil
+	})
+	fs := NewFlagSet("test", "ILC_INPUT_")
+	fs.Var(branch)
+	m := &tuiModel{
+		inputs:       []*Input{branch},
+		flagSet:      fs,
+		currentIndex: -1,
+	}
+	_, cmd := m.advance(0)
+	assert.Equal(t, []string{"main", "develop"}, m.textInput.AvailableSuggestions())
+	if assert.NotNil(t, cmd) {
+		msg := cmd()
+		assert.Equal(t, SuggestionsLoadedMsg{Input: branch, Suggestions: []string{"main", "develop", "release"}}, msg)
+		_, _ = m.Update(msg)
+	}
+	assert.Equal(t, []string{"main", "develop", "release"}, m.textInput.AvailableSuggestions())
+
+	_, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("re")})
+	_, _ = m.Update(tea.KeyMsg{Type: tea.KeyTab})
+	assert.Equal(t, "release", m.textInput.Value())
+}
+
+func TestTuiModel_AllowOther(t *testing.T) {
+	target := &Input{Name: "target", AllowOther: true, Options: InputOptions{{Label: "main", Value: "main"}, {Label: "next", Value: "next"}}, Value: &StringValue{Value: "fix/login", Pattern: "^[a-z/]+$"}}
+	fs := NewFlagSet("test", "ILC_INPUT_")
+	fs.Var(target)
+	m := &tuiModel{
+		inputs:       []*Input{target},
+		flagSet:      fs,
+		currentIndex: -1,
+	}
+	m.advance(0)
+	assert.Contains(t, m.View(), OtherLabel)
+
+	_, _ = m.Update(tea.KeyMsg{Type: tea.KeyUp})
+	_, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	assert.True(t, m.other)
+	assert.Equal(t, "fix/login", m.textInput.Value(), "other values start from the value")
+	assert.Contains(t, m.View(), "Please specify a target")
+
+	_, _ = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	assert.False(t, m.other, "escape returns to the options")
+	assert.False(t, m.aborted)
+
+	_, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m.textInput.SetValue("Bad")
+	_, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	assert.Error(t, m.err)
+
+	m.textInput.SetValue("release")
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	assert.NoError(t, m.err)
+	assert.Equal(t, "release", target.Value.String())
+	assert.NotNil(t, cmd)
+}
+
+func TestTuiModel_Transform(t *testing.T) {
+	name := &Input{Name: "name", Value: &StringValue{}, Transforms: []Transform{transformFunc(func(s string, values map[string]any) (string, error) {
+		return strings.ToUpper(s), nil
+	})}}
+	fs := NewFlagSet("test", "ILC_INPUT_")
+	fs.Var(name)
+	m := &tuiModel{
+		inputs:       []*Input{name},
+		flagSet:      fs,
+		currentIndex: -1,
+	}
+	m.advance(0)
+	_, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("web")})
+	_, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	assert.NoError(t, m.err)
+	assert.Equal(t, "WEB", name.Value.String())
+}
+
+func TestTuiModel_TransformOnce(t *testing.T) {
+	host := &Input{Name: "host", Optional: true, Value: &StringValue{Value: "h"}, Transforms: []Transform{transformFunc(func(s string, values map[string]any) (string, error) {
+		return s + ".example.com", nil
+	})}}
+	fs := NewFlagSet("test", "ILC_INPUT_")
+	fs.Var(host)
+	m := &tuiModel{
+		inputs:       []*Input{host},
+		flagSet:      fs,
+		currentIndex: -1,
+	}
+	for range 2 {
+		m.currentIndex = -1
+		m.advance(0)
+		assert.Equal(t, "h", m.textInput.Value(), "the value as given is prefilled")
+		_, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		assert.NoError(t, m.err)
+		assert.Equal(t, "h.example.com", host.Value.String(), "confirming again doesn't transform twice")
+	}
+
+	host.Recorded = []string{"r.example.com"}
+	host.DefaultFrom = defaultFunc(func(map[string]any) (string, error) {
+		return "r.example.com", nil
+	})
+	m.currentIndex = -1
+	m.advance(0)
+	assert.Equal(t, "r.example.com", m.textInput.Value(), "recorded values are prefilled as they are")
+	_, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	assert.NoError(t, m.err)
+	assert.Equal(t, "r.example.com", host.Value.String(), "recorded values aren't transformed again")
+}
+
+func TestTuiModel_FormatPlaceholder(t *testing.T) {
+	email := &Input{Name: "email", Value: &StringValue{Format: "email"}}
+	host := &Input{Name: "host", Value: &StringValue{Value: "localhost", Format: "hostname"}}
+	m := &tuiModel{
+		inputs:       []*Input{email, host},
+		currentIndex: -1,
+	}
+	m.advance(0)
+	assert.Equal(t, "an email address like name@example.com", m.textInput.Placeholder)
+	assert.Equal(t, "localhost", Placeholder(host))
+}
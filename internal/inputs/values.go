@@ -2,10 +2,18 @@ package inputs
 
 import (
 	"errors"
+	"fmt"
 	"math"
+	"net/mail"
+	"net/netip"
+	"net/url"
+	"os"
+	"path/filepath"
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
+	"unicode/utf8"
 )
 
 var (
@@ -26,6 +34,18 @@ type AdjustableValue interface {
 	Adjust(currentVal string, delta float64) (string, error)
 }
 
+// Hinter is implemented by values that can describe what they expect, which
+// is shown in place of an empty value.
+type Hinter interface {
+	Hint() string
+}
+
+// Completer is implemented by values that can suggest completions of what has
+// been typed so far.
+type Completer interface {
+	Complete(prefix string) []string
+}
+
 func isIncompleteNumber(s string) bool {
 	if s == "" || s == "-" || s == "+" || s == "." || s == "-." || s == "+." {
 		return true
@@ -41,9 +61,15 @@ func isIncompleteNumber(s string) bool {
 	return false
 }
 
+// StringValue holds text, which spans several lines when Multiline is set.
+// Its length is counted in characters.
 type StringValue struct {
-	Value   string `yaml:"default"`
-	Pattern string
+	Value     string `yaml:"default"`
+	Pattern   string
+	Multiline bool   `yaml:"multiline"`
+	MinLength int    `yaml:"min_length"`
+	MaxLength int    `yaml:"max_length"`
+	Format    string `yaml:"format"`
 }
 
 func (v StringValue) String() string {
@@ -55,13 +81,27 @@ func (v StringValue) Get() any {
 }
 
 func (v *StringValue) Set(s string) error {
+	if n := utf8.RuneCountInString(s); n < v.MinLength {
+		return fmt.Errorf("%w: must be at least %d characters", ErrInvalidValue, v.MinLength)
+	} else if v.MaxLength > 0 && n > v.MaxLength {
+		return fmt.Errorf("%w: must be at most %d characters", ErrInvalidValue, v.MaxLength)
+	}
+	if v.Format != "" {
+		format, found := stringFormats[v.Format]
+		if !found {
+			return fmt.Errorf("unknown format %q", v.Format)
+		}
+		if !format.valid(s) {
+			return fmt.Errorf("%w: must be %s", ErrInvalidValue, format)
+		}
+	}
 	if v.Pattern != "" {
 		matched, err := regexp.MatchString(v.Pattern, s)
 		if err != nil {
 			return err
 		}
 		if !matched {
-			return ErrInvalidValue
+			return fmt.Errorf("%w: must match %s", ErrInvalidValue, v.Pattern)
 		}
 	}
 	v.Value = s
@@ -69,22 +109,151 @@ func (v *StringValue) Set(s string) error {
 }
 
 func (v StringValue) ValidateLive(s string) error {
-	temp := &StringValue{Pattern: v.Pattern}
+	temp := v
 	return temp.Set(s)
 }
 
+// Hint describes the format of the value, when it has one.
+func (v StringValue) Hint() string {
+	if format, found := stringFormats[v.Format]; found {
+		return format.String()
+	}
+	return ""
+}
+
+// stringFormat is a built-in format of string values, described by what it
+// is and an example.
+type stringFormat struct {
+	name    string
+	example string
+	valid   func(string) bool
+}
+
+func (f stringFormat) String() string {
+	return fmt.Sprintf("%s like %s", f.name, f.example)
+}
+
+var (
+	hostnameLabelPattern = regexp.MustCompile(`^[a-zA-Z0-9]([a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?$`)
+	semverPattern        = regexp.MustCompile(`^(0|[1-9]\d*)\.(0|[1-9]\d*)\.(0|[1-9]\d*)(-(0|[1-9]\d*|\d*[a-zA-Z-][0-9a-zA-Z-]*)(\.(0|[1-9]\d*|\d*[a-zA-Z-][0-9a-zA-Z-]*))*)?(\+[0-9a-zA-Z-]+(\.[0-9a-zA-Z-]+)*)?$`)
+	uuidPattern          = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+)
+
+var stringFormats = map[string]stringFormat{
+	"email": {"an email address", "name@example.com", func(s string) bool {
+		address, err := mail.ParseAddress(s)
+		return err == nil && address.Address == s
+	}},
+	"url": {"a URL", "https://example.com", func(s string) bool {
+		u, err := url.Parse(s)
+		return err == nil && u.Scheme != "" && u.Host != ""
+	}},
+	"hostname": {"a hostname", "host.example.com", func(s string) bool {
+		if len(s) > 253 {
+			return false
+		}
+		for _, label := range strings.Split(strings.TrimSuffix(s, "."), ".") {
+			if !hostnameLabelPattern.MatchString(label) {
+				return false
+			}
+		}
+		return true
+	}},
+	"ipv4": {"an IPv4 address", "192.0.2.1", func(s string) bool {
+		addr, err := netip.ParseAddr(s)
+		return err == nil && addr.Is4()
+	}},
+	"ipv6": {"an IPv6 address", "2001:db8::1", func(s string) bool {
+		addr, err := netip.ParseAddr(s)
+		return err == nil && addr.Is6()
+	}},
+	"cidr": {"a CIDR range", "192.0.2.0/24", func(s string) bool {
+		_, err := netip.ParsePrefix(s)
+		return err == nil
+	}},
+	"semver": {"a semantic version", "1.2.3", semverPattern.MatchString},
+	"uuid":   {"a UUID", "123e4567-e89b-12d3-a456-426614174000", uuidPattern.MatchString},
+	"port": {"a port number", "8080", func(s string) bool {
+		n, err := strconv.ParseUint(s, 10, 16)
+		return err == nil && n > 0
+	}},
+}
+
+// IsStringFormat reports whether name is one of the built-in formats of string
+// values.
+func IsStringFormat(name string) bool {
+	_, found := stringFormats[name]
+	return found
+}
+
+// FileRefPrefix marks a value given for a multiline input as the path of the
+// file to read it from, ie. `@notes.md`.
+const FileRefPrefix = "@"
+
+// readFileRef returns the contents of the file s refers to, without its final
+// newline, when it starts with the prefix. Doubling the prefix escapes it.
+func readFileRef(s string) (string, error) {
+	if !strings.HasPrefix(s, FileRefPrefix) {
+		return s, nil
+	}
+	s = strings.TrimPrefix(s, FileRefPrefix)
+	if strings.HasPrefix(s, FileRefPrefix) {
+		return s, nil
+	}
+	content, err := os.ReadFile(s)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(string(content), "\n"), nil
+}
+
+// escapeFileRef escapes the prefix of text so it is not read as a file.
+func escapeFileRef(s string) string {
+	if strings.HasPrefix(s, FileRefPrefix) {
+		return FileRefPrefix + s
+	}
+	return s
+}
+
+// NumberValue holds a float. Its Step is how much it is adjusted by, and its
+// Precision the number of decimals it is rounded to when set.
 type NumberValue struct {
-	Value    float64 `yaml:"default"`
-	MinValue float64 `yaml:"min"`
-	MaxValue float64 `yaml:"max"`
+	Value     float64 `yaml:"default"`
+	MinValue  float64 `yaml:"min"`
+	MaxValue  float64 `yaml:"max"`
+	Step      float64 `yaml:"step"`
+	Precision *int    `yaml:"precision"`
 }
 
 func (v NumberValue) String() string {
+	return v.format(v.Value)
+}
+
+// format writes n with the precision, or without decimals for whole numbers
+// when there is no precision.
+func (v NumberValue) format(n float64) string {
 	prec := 5
-	if v.Value == math.Round(v.Value) {
+	if v.Precision != nil {
+		prec = *v.Precision
+	} else if n == math.Round(n) {
 		prec = 0
 	}
-	return strconv.FormatFloat(v.Value, 'f', prec, 64)
+	return strconv.FormatFloat(n, 'f', prec, 64)
+}
+
+func (v NumberValue) round(n float64) float64 {
+	if v.Precision == nil {
+		return n
+	}
+	scale := math.Pow10(*v.Precision)
+	return math.Round(n*scale) / scale
+}
+
+func (v NumberValue) step() float64 {
+	if v.Step <= 0 {
+		return 1
+	}
+	return v.Step
 }
 
 func (v NumberValue) Get() any {
@@ -96,6 +265,7 @@ func (v *NumberValue) Set(s string) error {
 	if err != nil {
 		return err
 	}
+	n = v.round(n)
 	if v.MinValue < v.MaxValue {
 		if n < v.MinValue || n > v.MaxValue {
 			return ErrInvalidValue
@@ -113,7 +283,7 @@ func (v NumberValue) ValidateLive(s string) error {
 	if isIncompleteNumber(s) {
 		return nil
 	}
-	temp := &NumberValue{MinValue: v.MinValue, MaxValue: v.MaxValue}
+	temp := &NumberValue{MinValue: v.MinValue, MaxValue: v.MaxValue, Precision: v.Precision}
 	return temp.Set(s)
 }
 
@@ -127,7 +297,7 @@ func (v *NumberValue) Adjust(currentVal string, delta float64) (string, error) {
 		n = v.Value
 	}
 
-	newVal := n + delta
+	newVal := v.round(n + delta*v.step())
 
 	// Clamping to min/max bounds if defined
 	if v.MinValue < v.MaxValue {
@@ -143,17 +313,81 @@ func (v *NumberValue) Adjust(currentVal string, delta float64) (string, error) {
 	}
 
 	// Format back cleanly (avoiding .00000 decimals for integers)
-	prec := 5
-	if newVal == math.Round(newVal) {
-		prec = 0
-	}
-	newStr := strconv.FormatFloat(newVal, 'f', prec, 64)
+	newStr := v.format(newVal)
 
 	// Dry-run check
-	temp := &NumberValue{MinValue: v.MinValue, MaxValue: v.MaxValue}
+	temp := &NumberValue{MinValue: v.MinValue, MaxValue: v.MaxValue, Precision: v.Precision}
+	return newStr, temp.Set(newStr)
+}
+
+// IntegerValue holds a whole number, adjusted by its Step.
+type IntegerValue struct {
+	Value    int64 `yaml:"default"`
+	MinValue int64 `yaml:"min"`
+	MaxValue int64 `yaml:"max"`
+	Step     int64 `yaml:"step"`
+}
+
+func (v IntegerValue) String() string {
+	return strconv.FormatInt(v.Value, 10)
+}
+
+func (v IntegerValue) Get() any {
+	return v.Value
+}
+
+func (v *IntegerValue) Set(s string) error {
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return err
+	}
+	if !v.inRange(n) {
+		return ErrInvalidValue
+	}
+	v.Value = n
+	return nil
+}
+
+func (v IntegerValue) ValidateLive(s string) error {
+	if s == "" || s == "-" || s == "+" {
+		return nil
+	}
+	temp := &IntegerValue{MinValue: v.MinValue, MaxValue: v.MaxValue}
+	return temp.Set(s)
+}
+
+func (v *IntegerValue) Adjust(currentVal string, delta float64) (string, error) {
+	n, err := strconv.ParseInt(currentVal, 10, 64)
+	if currentVal == "" || err != nil {
+		n = v.Value
+	}
+	step := v.Step
+	if step <= 0 {
+		step = 1
+	}
+	newVal := n + int64(delta)*step
+
+	// Clamping to min/max bounds if defined, like numbers
+	if v.MinValue < v.MaxValue {
+		newVal = max(v.MinValue, min(newVal, v.MaxValue))
+	} else if v.MinValue > v.MaxValue && v.MaxValue == 0 {
+		newVal = max(v.MinValue, newVal)
+	}
+
+	newStr := strconv.FormatInt(newVal, 10)
+	temp := &IntegerValue{MinValue: v.MinValue, MaxValue: v.MaxValue}
 	return newStr, temp.Set(newStr)
 }
 
+func (v IntegerValue) inRange(n int64) bool {
+	if v.MinValue < v.MaxValue {
+		return n >= v.MinValue && n <= v.MaxValue
+	} else if v.MinValue > v.MaxValue && v.MaxValue == 0 {
+		return n >= v.MinValue
+	}
+	return true
+}
+
 type BooleanValue struct {
 	Value bool `yaml:"default"`
 }
@@ -179,3 +413,457 @@ func (v BooleanValue) ValidateLive(s string) error {
 	temp := &BooleanValue{}
 	return temp.Set(s)
 }
+
+// DefaultListSeparator separates the items of a list given as an argument, or
+// as an environment variable unless another separator is set.
+const DefaultListSeparator = ","
+
+// ListValue holds several items, given as a comma separated value or by
+// repeating its option. The items are joined by the separator in environment
+// variables.
+type ListValue struct {
+	Values    []string `yaml:"default"`
+	MinItems  int      `yaml:"min_items"`
+	MaxItems  int      `yaml:"max_items"`
+	Separator string   `yaml:"separator"`
+}
+
+func (v ListValue) String() string {
+	return strings.Join(v.Values, DefaultListSeparator)
+}
+
+func (v ListValue) Get() any {
+	return append([]string{}, v.Values...)
+}
+
+func (v *ListValue) Set(s string) error {
+	return v.SetItems(splitList(s, DefaultListSeparator))
+}
+
+// SetItems replaces the items, checking there are as many as allowed.
+func (v *ListValue) SetItems(items []string) error {
+	if err := v.check(items); err != nil {
+		return err
+	}
+	v.Values = items
+	return nil
+}
+
+// EnvString returns the items joined by the separator.
+func (v ListValue) EnvString() string {
+	return strings.Join(v.Values, v.separator())
+}
+
+// SplitEnv returns the items of an environment variable value.
+func (v ListValue) SplitEnv(s string) []string {
+	return splitList(s, v.separator())
+}
+
+func (v ListValue) ValidateLive(s string) error {
+	return v.check(splitList(s, DefaultListSeparator))
+}
+
+func (v ListValue) separator() string {
+	if v.Separator == "" {
+		return DefaultListSeparator
+	}
+	return v.Separator
+}
+
+func (v ListValue) check(items []string) error {
+	if n := len(items); n < v.MinItems {
+		return fmt.Errorf("%w: expected at least %d items, got %d", ErrInvalidValue, v.MinItems, n)
+	} else if v.MaxItems > 0 && n > v.MaxItems {
+		return fmt.Errorf("%w: expected at most %d items, got %d", ErrInvalidValue, v.MaxItems, n)
+	}
+	return nil
+}
+
+// splitList splits s into its trimmed, non-empty items
+func splitList(s, sep string) []string {
+	items := []string{}
+	for _, item := range strings.Split(s, sep) {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+const (
+	PathKindFile = "file"
+	PathKindDir  = "dir"
+)
+
+// PathValue holds a filesystem path. Relative paths are resolved against its
+// Base, or the working directory when it has none, and Get returns the
+// absolute path. Its Kind restricts it to files or directories.
+type PathValue struct {
+	Value      string   `yaml:"default"`
+	MustExist  bool     `yaml:"must_exist"`
+	Kind       string   `yaml:"kind"`
+	Extensions []string `yaml:"extensions"`
+	Base       string   `yaml:"base"`
+	Picker     bool     `yaml:"picker"`
+}
+
+func (v PathValue) String() string {
+	return v.Value
+}
+
+func (v PathValue) Get() any {
+	return v.Abs(v.Value)
+}
+
+func (v *PathValue) Set(s string) error {
+	if err := v.check(s); err != nil {
+		return err
+	}
+	v.Value = s
+	return nil
+}
+
+func (v PathValue) ValidateLive(s string) error {
+	return v.check(s)
+}
+
+// Abs returns the absolute path of s, expanding a leading ~ to the home
+// directory.
+func (v PathValue) Abs(s string) string {
+	if s == "" {
+		return ""
+	}
+	if s == "~" || strings.HasPrefix(s, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			s = filepath.Join(home, s[1:])
+		}
+	}
+	if !filepath.IsAbs(s) {
+		s = filepath.Join(v.Base, s)
+	}
+	if abs, err := filepath.Abs(s); err == nil {
+		return abs
+	}
+	return filepath.Clean(s)
+}
+
+// Complete returns the entries of the directory being typed that start with
+// what follows its last separator. Directories end with a separator so they
+// can be completed further.
+func (v PathValue) Complete(prefix string) []string {
+	dir, partial := "", prefix
+	if i := strings.LastIndex(prefix, string(filepath.Separator)); i >= 0 {
+		dir, partial = prefix[:i+1], prefix[i+1:]
+	}
+	path := v.Abs(dir)
+	if dir == "" {
+		path = v.Abs(".")
+	}
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil
+	}
+	completions := []string{}
+	for _, entry := range entries {
+		name := entry.Name()
+		if !strings.HasPrefix(name, partial) || (strings.HasPrefix(name, ".") && !strings.HasPrefix(partial, ".")) {
+			continue
+		}
+		if isDir(filepath.Join(path, name)) {
+			completions = append(completions, dir+name+string(filepath.Separator))
+		} else if v.Kind != PathKindDir && v.hasExtension(name) {
+			completions = append(completions, dir+name)
+		}
+	}
+	return completions
+}
+
+func (v PathValue) check(s string) error {
+	if s == "" {
+		if v.MustExist {
+			return fmt.Errorf("%w: a path is required", ErrInvalidValue)
+		}
+		return nil
+	}
+	path := v.Abs(s)
+	info, err := os.Stat(path)
+	if err != nil {
+		if v.MustExist {
+			return fmt.Errorf("%w: %s does not exist", ErrInvalidValue, s)
+		}
+	} else if v.Kind == PathKindFile && info.IsDir() {
+		return fmt.Errorf("%w: %s is a directory", ErrInvalidValue, s)
+	} else if v.Kind == PathKindDir && !info.IsDir() {
+		return fmt.Errorf("%w: %s is not a directory", ErrInvalidValue, s)
+	}
+	if (err != nil || !info.IsDir()) && v.Kind != PathKindDir && !v.hasExtension(path) {
+		return fmt.Errorf("%w: %s must end with %s", ErrInvalidValue, s, strings.Join(v.Extensions, ", "))
+	}
+	return nil
+}
+
+// hasExtension reports whether the name ends with one of the extensions, or
+// whether there are none to match.
+func (v PathValue) hasExtension(name string) bool {
+	if len(v.Extensions) == 0 {
+		return true
+	}
+	ext := filepath.Ext(name)
+	for _, allowed := range v.Extensions {
+		if strings.EqualFold(ext, "."+strings.TrimPrefix(allowed, ".")) {
+			return true
+		}
+	}
+	return false
+}
+
+func isDir(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
+
+const (
+	DefaultDateFormat     = "2006-01-02"
+	DefaultDateTimeFormat = "2006-01-02 15:04"
+	DefaultDurationStep   = time.Minute
+)
+
+// now is the clock relative dates are resolved against
+var now = time.Now
+
+var (
+	relativeTimePattern = regexp.MustCompile(`^(now|today|tomorrow|yesterday)\s*(?:([+-])\s*(.+))?$`)
+	durationDaysPattern = regexp.MustCompile(`^(?:(\d+)w)?(?:(\d+)d)?(.*)$`)
+)
+
+// DateValue holds a date, or a date and time when WithTime is set, written in
+// its Format. It and its bounds can be relative to now, ie. "today+7d", and
+// Up/Down adjusts it by a day, or an hour with time.
+type DateValue struct {
+	Value    time.Time `yaml:"-"`
+	Format   string    `yaml:"format"`
+	MinValue string    `yaml:"min"`
+	MaxValue string    `yaml:"max"`
+	WithTime bool      `yaml:"-"`
+}
+
+func (v DateValue) String() string {
+	if v.Value.IsZero() {
+		return ""
+	}
+	return v.Value.Format(v.layout())
+}
+
+func (v DateValue) Get() any {
+	return v.Value
+}
+
+func (v *DateValue) Set(s string) error {
+	if s == "" {
+		v.Value = time.Time{}
+		return nil
+	}
+	t, err := v.parse(s)
+	if err != nil {
+		return err
+	}
+	if err := v.check(t); err != nil {
+		return err
+	}
+	v.Value = t
+	return nil
+}
+
+func (v DateValue) ValidateLive(s string) error {
+	temp := v
+	return temp.Set(s)
+}
+
+func (v *DateValue) Adjust(currentVal string, delta float64) (string, error) {
+	t, err := v.parse(currentVal)
+	if currentVal == "" || err != nil {
+		t = v.Value
+		if t.IsZero() {
+			t, _ = v.parse("now")
+		}
+	}
+	if v.WithTime {
+		t = t.Add(time.Duration(delta) * time.Hour)
+	} else {
+		t = t.AddDate(0, 0, int(delta))
+	}
+
+	// Clamping to min/max bounds if defined
+	if minValue, err := v.parse(v.MinValue); err == nil && t.Before(minValue) {
+		t = minValue
+	} else if maxValue, err := v.parse(v.MaxValue); err == nil && t.After(maxValue) {
+		t = maxValue
+	}
+
+	newStr := t.Format(v.layout())
+	temp := *v
+	return newStr, temp.Set(newStr)
+}
+
+func (v DateValue) layout() string {
+	if v.Format != "" {
+		return v.Format
+	}
+	if v.WithTime {
+		return DefaultDateTimeFormat
+	}
+	return DefaultDateFormat
+}
+
+// parse reads s in the layout or relative to now, dropping what the layout
+// leaves out so relative dates compare like typed ones.
+func (v DateValue) parse(s string) (time.Time, error) {
+	layout := v.layout()
+	t, err := parseTime(s, layout)
+	if err != nil {
+		return t, fmt.Errorf("%w: expected a date like %s", ErrInvalidValue, now().Format(layout))
+	}
+	return time.ParseInLocation(layout, t.Format(layout), time.Local)
+}
+
+func (v DateValue) check(t time.Time) error {
+	if minValue, err := v.parse(v.MinValue); err == nil && t.Before(minValue) {
+		return fmt.Errorf("%w: must not be before %s", ErrInvalidValue, minValue.Format(v.layout()))
+	}
+	if maxValue, err := v.parse(v.MaxValue); err == nil && t.After(maxValue) {
+		return fmt.Errorf("%w: must not be after %s", ErrInvalidValue, maxValue.Format(v.layout()))
+	}
+	return nil
+}
+
+// parseTime parses s in the layout, or relative to now such as "today+7d" or
+// "now-2h".
+func parseTime(s, layout string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	m := relativeTimePattern.FindStringSubmatch(strings.ToLower(s))
+	if m == nil {
+		return time.ParseInLocation(layout, s, time.Local)
+	}
+	t := now()
+	if m[1] != "now" {
+		t = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	}
+	switch m[1] {
+	case "tomorrow":
+		t = t.AddDate(0, 0, 1)
+	case "yesterday":
+		t = t.AddDate(0, 0, -1)
+	}
+	if m[2] == "" {
+		return t, nil
+	}
+	days, d, err := parseDuration(m[3])
+	if err != nil {
+		return time.Time{}, err
+	}
+	if m[2] == "-" {
+		days, d = -days, -d
+	}
+	// Whole days keep the time of day across daylight saving changes
+	return t.AddDate(0, 0, days).Add(d), nil
+}
+
+// DurationValue holds a length of time such as "1h30m", which can also be
+// given in days and weeks, ie. "1w2d". Up/Down adjusts it by its Step.
+type DurationValue struct {
+	Value    time.Duration `yaml:"-"`
+	MinValue string        `yaml:"min"`
+	MaxValue string        `yaml:"max"`
+	Step     string        `yaml:"step"`
+}
+
+func (v DurationValue) String() string {
+	s := v.Value.String()
+	if strings.HasSuffix(s, "m0s") {
+		s = s[:len(s)-2]
+	}
+	if strings.HasSuffix(s, "h0m") {
+		s = s[:len(s)-2]
+	}
+	return s
+}
+
+func (v DurationValue) Get() any {
+	return v.Value
+}
+
+func (v *DurationValue) Set(s string) error {
+	d, err := ParseDuration(s)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidValue, err)
+	}
+	if err := v.check(d); err != nil {
+		return err
+	}
+	v.Value = d
+	return nil
+}
+
+func (v DurationValue) ValidateLive(s string) error {
+	temp := v
+	return temp.Set(s)
+}
+
+func (v *DurationValue) Adjust(currentVal string, delta float64) (string, error) {
+	d, err := ParseDuration(currentVal)
+	if currentVal == "" || err != nil {
+		d = v.Value
+	}
+	step, err := ParseDuration(v.Step)
+	if v.Step == "" || err != nil || step <= 0 {
+		step = DefaultDurationStep
+	}
+	d += time.Duration(delta) * step
+
+	// Clamping to min/max bounds if defined, never going below zero
+	minValue, _ := ParseDuration(v.MinValue)
+	if maxValue, err := ParseDuration(v.MaxValue); err == nil && v.MaxValue != "" && d > maxValue {
+		d = maxValue
+	}
+	d = max(d, minValue, 0)
+
+	newStr := DurationValue{Value: d}.String()
+	temp := *v
+	return newStr, temp.Set(newStr)
+}
+
+func (v DurationValue) check(d time.Duration) error {
+	if minValue, err := ParseDuration(v.MinValue); err == nil && v.MinValue != "" && d < minValue {
+		return fmt.Errorf("%w: must be at least %s", ErrInvalidValue, DurationValue{Value: minValue})
+	}
+	if maxValue, err := ParseDuration(v.MaxValue); err == nil && v.MaxValue != "" && d > maxValue {
+		return fmt.Errorf("%w: must be at most %s", ErrInvalidValue, DurationValue{Value: maxValue})
+	}
+	return nil
+}
+
+// ParseDuration parses a duration like time.ParseDuration, also accepting
+// leading weeks and days, ie. "1w2d12h".
+func ParseDuration(s string) (time.Duration, error) {
+	days, d, err := parseDuration(s)
+	return time.Duration(days)*24*time.Hour + d, err
+}
+
+func parseDuration(s string) (int, time.Duration, error) {
+	s = strings.TrimSpace(s)
+	m := durationDaysPattern.FindStringSubmatch(s)
+	if s == "" || (m[1] == "" && m[2] == "") {
+		d, err := time.ParseDuration(s)
+		return 0, d, err
+	}
+	weeks, _ := strconv.Atoi(m[1])
+	days, _ := strconv.Atoi(m[2])
+	var d time.Duration
+	if m[3] != "" {
+		var err error
+		if d, err = time.ParseDuration(m[3]); err != nil {
+			return 0, 0, fmt.Errorf("invalid duration %q", s)
+		}
+	}
+	return weeks*7 + days, d, nil
+}